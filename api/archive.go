@@ -0,0 +1,190 @@
+package api
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Kinds of archives that can be browsed
+const (
+	ZipArchive   = "zip"
+	TarArchive   = "tar"
+	TarGzArchive = "tar.gz"
+)
+
+// A file or directory inside of an archive, directory names end with a slash like s3 prefixes
+type ArchiveEntry struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+func (e ArchiveEntry) IsDir() bool {
+	return strings.HasSuffix(e.Name, "/")
+}
+
+// Returns the kind of archive key looks like, empty when it is not one
+func GetArchiveKind(key string) string {
+	k := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(k, ".zip"), strings.HasSuffix(k, ".jar"), strings.HasSuffix(k, ".war"):
+		return ZipArchive
+	case strings.HasSuffix(k, ".tar"):
+		return TarArchive
+	case strings.HasSuffix(k, ".tar.gz"), strings.HasSuffix(k, ".tgz"):
+		return TarGzArchive
+	}
+
+	return ""
+}
+
+// Lists the files of an archive.  Zips only have their central directory read, tars are read from the
+// start but the contents of plain tars are skipped over with seeks.
+func ListArchive(session *session.Session, bucket, key string, size int64) ([]ArchiveEntry, error) {
+	r := NewObjectReader(session, bucket, key, size)
+	entries := make([]ArchiveEntry, 0)
+
+	if GetArchiveKind(key) == ZipArchive {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			entries = append(entries, ArchiveEntry{
+				Name:     cleanArchiveName(f.Name, f.FileInfo().IsDir()),
+				Size:     int64(f.UncompressedSize64),
+				Modified: f.Modified,
+			})
+		}
+		return entries, nil
+	}
+
+	err := walkTar(r, key, func(h *tar.Header, _ io.Reader) (bool, error) {
+		switch h.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+			entries = append(entries, ArchiveEntry{
+				Name:     cleanArchiveName(h.Name, h.Typeflag == tar.TypeDir),
+				Size:     h.Size,
+				Modified: h.ModTime,
+			})
+		}
+		return false, nil
+	})
+
+	return entries, err
+}
+
+// Writes the contents of the file name inside of an archive to w
+func WriteArchiveMember(session *session.Session, bucket, key string, size int64, name string, w io.Writer) error {
+	r := NewObjectReader(session, bucket, key, size)
+
+	if GetArchiveKind(key) == ZipArchive {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return err
+		}
+		for _, f := range zr.File {
+			if cleanArchiveName(f.Name, false) != name {
+				continue
+			}
+			fr, err := f.Open()
+			if err != nil {
+				return err
+			}
+			defer fr.Close()
+
+			_, err = io.Copy(w, fr)
+			return err
+		}
+		return fmt.Errorf("%s is not in %s", name, key)
+	}
+
+	found := false
+	err := walkTar(r, key, func(h *tar.Header, tr io.Reader) (bool, error) {
+		if h.Typeflag != tar.TypeReg || cleanArchiveName(h.Name, false) != name {
+			return false, nil
+		}
+		found = true
+		_, err := io.Copy(w, tr)
+		return true, err
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("%s is not in %s", name, key)
+	}
+
+	return err
+}
+
+// Reads a file inside of an archive into memory, failing once it gets bigger than limit
+func ReadArchiveMember(session *session.Session, bucket, key string, size int64, name string, limit int64) ([]byte, error) {
+	w := &limitedBuffer{limit: limit}
+	if err := WriteArchiveMember(session, bucket, key, size, name, w); err != nil {
+		return nil, err
+	}
+
+	return w.data, nil
+}
+
+var ErrMemberTooLarge = errors.New("the file is too large to be read into memory")
+
+type limitedBuffer struct {
+	data  []byte
+	limit int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if int64(len(b.data)+len(p)) > b.limit {
+		return 0, ErrMemberTooLarge
+	}
+	b.data = append(b.data, p...)
+
+	return len(p), nil
+}
+
+// Calls fn with every header of a tar until it returns true
+func walkTar(r *ObjectReader, key string, fn func(*tar.Header, io.Reader) (bool, error)) error {
+	var src io.Reader = r
+	if GetArchiveKind(key) == TarGzArchive {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		src = gr
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		done, err := fn(h, tr)
+		if done || err != nil {
+			return err
+		}
+	}
+}
+
+// Names are stored with or without a leading ./ or /, they are shown like s3 keys
+func cleanArchiveName(name string, isDir bool) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if isDir && name != "" {
+		name += "/"
+	}
+
+	return name
+}
@@ -1,10 +1,31 @@
 package api
 
 import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+var (
+	bucketNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+)
+
+// Settings chosen in the create bucket wizard
+type BucketOptions struct {
+	Name              string
+	Region            string
+	ObjectOwnership   string
+	BlockPublicAccess bool
+	Versioning        bool
+	Encryption        string // s3.ServerSideEncryptionAes256 or s3.ServerSideEncryptionAwsKms
+	KmsKeyId          string
+}
+
 func GetBuckets(session *session.Session) ([]*s3.Bucket, error) {
 	client := s3.New(session)
 	b, err := client.ListBuckets(&s3.ListBucketsInput{})
@@ -15,3 +36,190 @@ func GetBuckets(session *session.Session) ([]*s3.Bucket, error) {
 
 	return b.Buckets, nil
 }
+
+// Checks the name against the s3 bucket naming rules so the user gets a readable error before any
+// request is made.
+func ValidateBucketName(name string) error {
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		return fmt.Errorf("bucket names must be between 3 and 63 characters long")
+	case !bucketNameRegex.MatchString(name):
+		return fmt.Errorf("only lowercase letters, numbers, dots and hyphens are allowed and the name must start and end with a letter or number")
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("bucket names must not contain two adjacent periods")
+	case net.ParseIP(name) != nil:
+		return fmt.Errorf("bucket names must not be formatted as an IP address")
+	case strings.HasPrefix(name, "xn--") || strings.HasPrefix(name, "sthree-"):
+		return fmt.Errorf("bucket names must not start with xn-- or sthree-")
+	case strings.HasSuffix(name, "-s3alias") || strings.HasSuffix(name, "--ol-s3"):
+		return fmt.Errorf("bucket names must not end with -s3alias or --ol-s3")
+	}
+
+	return nil
+}
+
+// Creates the bucket and then applies the public access block, versioning and encryption settings
+// that can not be passed to CreateBucket directly.
+func CreateBucket(session *session.Session, opts BucketOptions) error {
+	client := s3.New(session, aws.NewConfig().WithRegion(opts.Region))
+
+	input := s3.CreateBucketInput{
+		Bucket:          &opts.Name,
+		ObjectOwnership: &opts.ObjectOwnership,
+	}
+	// us-east-1 is the default location and is rejected when passed explicitly
+	if opts.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: &opts.Region,
+		}
+	}
+
+	if _, err := client.CreateBucket(&input); err != nil {
+		return err
+	}
+
+	if err := client.WaitUntilBucketExists(&s3.HeadBucketInput{Bucket: &opts.Name}); err != nil {
+		return err
+	}
+
+	_, err := client.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
+		Bucket: &opts.Name,
+		PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(opts.BlockPublicAccess),
+			BlockPublicPolicy:     aws.Bool(opts.BlockPublicAccess),
+			IgnorePublicAcls:      aws.Bool(opts.BlockPublicAccess),
+			RestrictPublicBuckets: aws.Bool(opts.BlockPublicAccess),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.Versioning {
+		_, err = client.PutBucketVersioning(&s3.PutBucketVersioningInput{
+			Bucket: &opts.Name,
+			VersioningConfiguration: &s3.VersioningConfiguration{
+				Status: aws.String(s3.BucketVersioningStatusEnabled),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rule := s3.ServerSideEncryptionByDefault{SSEAlgorithm: &opts.Encryption}
+	if opts.Encryption == s3.ServerSideEncryptionAwsKms && opts.KmsKeyId != "" {
+		rule.KMSMasterKeyID = &opts.KmsKeyId
+	}
+	_, err = client.PutBucketEncryption(&s3.PutBucketEncryptionInput{
+		Bucket: &opts.Name,
+		ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: &rule}},
+		},
+	})
+
+	return err
+}
+
+// Returns the region the bucket lives in.  GetBucketLocation answers with an empty location for
+// us-east-1 and with "EU" for buckets created with the legacy eu-west-1 constraint.
+func GetBucketRegion(session *session.Session, bucket string) (string, error) {
+	client := s3.New(session)
+	o, err := client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: &bucket})
+	if err != nil {
+		return "", err
+	}
+
+	switch aws.StringValue(o.LocationConstraint) {
+	case "":
+		return "us-east-1", nil
+	case "EU":
+		return "eu-west-1", nil
+	}
+
+	return aws.StringValue(o.LocationConstraint), nil
+}
+
+// A client talking to the region the bucket lives in.  Presigned urls embed the region and some
+// bucket level requests are rejected when sent to another region.
+func getBucketClient(session *session.Session, bucket string) (*s3.S3, error) {
+	region, err := GetBucketRegion(session, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return s3.New(session, aws.NewConfig().WithRegion(region)), nil
+}
+
+// Counts every object version and delete marker in the bucket, used as the dry run of EmptyBucket
+func CountObjectVersions(session *session.Session, bucket string) (int, int64, error) {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var count int
+	var size int64
+	err = client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: &bucket}, func(o *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		count += len(o.Versions) + len(o.DeleteMarkers)
+		for _, v := range o.Versions {
+			size += aws.Int64Value(v.Size)
+		}
+		return true
+	})
+
+	return count, size, err
+}
+
+// Removes every object, version and delete marker so the bucket can be deleted.  Versions are
+// deleted one listing page (up to 1000) at a time and progress receives the running total.
+func EmptyBucket(session *session.Session, bucket string, progress func(done int)) ([]DeleteError, error) {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]DeleteError, 0)
+	done := 0
+	var deleteErr error
+
+	err = client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: &bucket}, func(o *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		ids := make([]*s3.ObjectIdentifier, 0, len(o.Versions)+len(o.DeleteMarkers))
+		for _, v := range o.Versions {
+			ids = append(ids, &s3.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, d := range o.DeleteMarkers {
+			ids = append(ids, &s3.ObjectIdentifier{Key: d.Key, VersionId: d.VersionId})
+		}
+
+		var f []DeleteError
+		f, deleteErr = deleteIdentifiers(client, bucket, ids, nil)
+		failed = append(failed, f...)
+		if deleteErr != nil {
+			return false
+		}
+
+		done += len(ids)
+		if progress != nil {
+			progress(done)
+		}
+		return true
+	})
+
+	if deleteErr != nil {
+		return failed, deleteErr
+	}
+
+	return failed, err
+}
+
+func DeleteBucket(session *session.Session, bucket string) error {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteBucket(&s3.DeleteBucketInput{Bucket: &bucket})
+
+	return err
+}
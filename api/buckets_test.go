@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestValidateBucketName(t *testing.T) {
+	tests := []struct {
+		name  string
+		isErr bool
+	}{
+		{"my-bucket", false},
+		{"my.bucket.2", false},
+		{"abc", false},
+		{"ab", true},
+		{"a123456789012345678901234567890123456789012345678901234567890123", true},
+		{"My-Bucket", true},
+		{"my_bucket", true},
+		{"-bucket", true},
+		{"bucket-", true},
+		{"my..bucket", true},
+		{"192.168.1.1", true},
+		{"xn--bucket", true},
+		{"sthree-bucket", true},
+		{"bucket-s3alias", true},
+		{"bucket--ol-s3", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateBucketName(tt.name); (err != nil) != tt.isErr {
+			t.Errorf("ValidateBucketName(%q) = %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// CopyObject refuses sources larger than this, anything bigger has to be copied in parts
+	maxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+	minCopyPartSize   int64 = 512 * 1024 * 1024
+	maxCopyParts      int64 = 10000
+)
+
+// How a copy treats the metadata and tags of the source object.  When a Replace flag is false the
+// values of the source are kept and the matching field is ignored.
+type CopyOptions struct {
+	ReplaceMetadata bool
+	ContentType     string
+	Metadata        map[string]string
+
+	ReplaceTags bool
+	Tags        map[string]string
+
+	StorageClass string // Empty keeps the class of the source
+
+	// Encryption of the copy, nil keeps the encryption of the source
+	ServerSideEncryption *string
+	SSEKMSKeyId          *string
+}
+
+// A single object to copy
+type CopyItem struct {
+	SourceBucket string
+	SourceKey    string
+	Size         int64
+	DestBucket   string
+	DestKey      string
+}
+
+// Splits s3://bucket/some/prefix into the bucket and the prefix
+func ParseS3Uri(uri string) (string, string, error) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return "", "", fmt.Errorf("%s is not an s3:// uri", uri)
+	}
+
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(uri, "s3://"), "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("%s does not contain a bucket", uri)
+	}
+
+	return bucket, prefix, nil
+}
+
+// Copies the object server side.  Objects over 5 GB are copied with a multipart upload built from
+// UploadPartCopy.  progress receives the number of bytes of this object copied so far.
+func CopyObject(session *session.Session, item CopyItem, opts CopyOptions, progress func(copied int64)) error {
+	client := s3.New(session)
+
+	// A copy does not keep the storage class or encryption of the source on its own
+	head, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: &item.SourceBucket,
+		Key:    &item.SourceKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	if item.Size > maxCopyObjectSize {
+		return copyObjectMultipart(client, item, head, opts, progress)
+	}
+
+	input := s3.CopyObjectInput{
+		Bucket:     &item.DestBucket,
+		Key:        &item.DestKey,
+		CopySource: aws.String(getCopySource(item.SourceBucket, item.SourceKey)),
+	}
+
+	// REPLACE resets every header that is not sent along, so the ones of the source are repeated
+	if opts.ReplaceMetadata {
+		input.MetadataDirective = aws.String(s3.MetadataDirectiveReplace)
+		input.Metadata = aws.StringMap(opts.Metadata)
+		input.ContentType = head.ContentType
+		input.CacheControl = head.CacheControl
+		input.ContentDisposition = head.ContentDisposition
+		input.ContentEncoding = head.ContentEncoding
+		input.ContentLanguage = head.ContentLanguage
+		if opts.ContentType != "" {
+			input.ContentType = &opts.ContentType
+		}
+	}
+
+	input.StorageClass, input.ServerSideEncryption, input.SSEKMSKeyId = getCopyAttributes(head, opts)
+
+	if opts.ReplaceTags {
+		input.TaggingDirective = aws.String(s3.TaggingDirectiveReplace)
+		input.Tagging = aws.String(EncodeTags(opts.Tags))
+	}
+
+	_, err = client.CopyObject(&input)
+	if err == nil && progress != nil {
+		progress(item.Size)
+	}
+
+	return err
+}
+
+// Copies every item and returns how many bytes were copied.  progress is called with the overall
+// number of bytes copied and the item being worked on.
+func CopyObjects(session *session.Session, items []CopyItem, opts CopyOptions, progress func(copied int64, item CopyItem)) (int64, error) {
+	var copied int64
+
+	for _, i := range items {
+		if progress != nil {
+			progress(copied, i)
+		}
+
+		start := copied
+		err := CopyObject(session, i, opts, func(c int64) {
+			if progress != nil {
+				progress(start+c, i)
+			}
+		})
+		if err != nil {
+			return copied, fmt.Errorf("copying %s: %w", i.SourceKey, err)
+		}
+
+		copied = start + i.Size
+	}
+
+	return copied, nil
+}
+
+// The class and encryption of the source unless opts sets them.  HeadObject leaves the class out for
+// STANDARD objects, which is also what a copy without a class gets.
+func getCopyAttributes(head *s3.HeadObjectOutput, opts CopyOptions) (*string, *string, *string) {
+	class := head.StorageClass
+	if opts.StorageClass != "" {
+		class = &opts.StorageClass
+	}
+
+	if opts.ServerSideEncryption != nil {
+		return class, opts.ServerSideEncryption, opts.SSEKMSKeyId
+	}
+
+	return class, head.ServerSideEncryption, head.SSEKMSKeyId
+}
+
+// Multipart uploads do not carry over anything from the source, so metadata and tags are taken from
+// the source unless they are being replaced.
+func copyObjectMultipart(client *s3.S3, item CopyItem, head *s3.HeadObjectOutput, opts CopyOptions, progress func(copied int64)) error {
+	create := s3.CreateMultipartUploadInput{
+		Bucket:             &item.DestBucket,
+		Key:                &item.DestKey,
+		ContentType:        head.ContentType,
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+		Metadata:           head.Metadata,
+	}
+
+	if opts.ReplaceMetadata {
+		create.Metadata = aws.StringMap(opts.Metadata)
+		if opts.ContentType != "" {
+			create.ContentType = &opts.ContentType
+		}
+	}
+
+	create.StorageClass, create.ServerSideEncryption, create.SSEKMSKeyId = getCopyAttributes(head, opts)
+
+	if opts.ReplaceTags {
+		create.Tagging = aws.String(EncodeTags(opts.Tags))
+	} else {
+		t, err := client.GetObjectTagging(&s3.GetObjectTaggingInput{
+			Bucket: &item.SourceBucket,
+			Key:    &item.SourceKey,
+		})
+		if err != nil {
+			return err
+		}
+		if len(t.TagSet) > 0 {
+			create.Tagging = aws.String(EncodeTags(TagSetToMap(t.TagSet)))
+		}
+	}
+
+	upload, err := client.CreateMultipartUpload(&create)
+	if err != nil {
+		return err
+	}
+
+	partSize := minCopyPartSize
+	if item.Size/maxCopyParts >= partSize {
+		partSize = item.Size/maxCopyParts + 1
+	}
+
+	parts := make([]*s3.CompletedPart, 0)
+	source := getCopySource(item.SourceBucket, item.SourceKey)
+
+	for start, n := int64(0), int64(1); start < item.Size; start, n = start+partSize, n+1 {
+		end := start + partSize - 1
+		if end >= item.Size {
+			end = item.Size - 1
+		}
+
+		p, err := client.UploadPartCopy(&s3.UploadPartCopyInput{
+			Bucket:          &item.DestBucket,
+			Key:             &item.DestKey,
+			UploadId:        upload.UploadId,
+			PartNumber:      aws.Int64(n),
+			CopySource:      &source,
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+		if err != nil {
+			client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+				Bucket:   &item.DestBucket,
+				Key:      &item.DestKey,
+				UploadId: upload.UploadId,
+			})
+			return err
+		}
+
+		parts = append(parts, &s3.CompletedPart{
+			ETag:       p.CopyPartResult.ETag,
+			PartNumber: aws.Int64(n),
+		})
+
+		if progress != nil {
+			progress(end + 1)
+		}
+	}
+
+	_, err = client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          &item.DestBucket,
+		Key:             &item.DestKey,
+		UploadId:        upload.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   &item.DestBucket,
+			Key:      &item.DestKey,
+			UploadId: upload.UploadId,
+		})
+	}
+
+	return err
+}
+
+// CopySource is "bucket/key" url encoded, slashes are left as they are.  S3 decodes + as a space so
+// every reserved character is escaped, not only the ones a path would need.
+func getCopySource(bucket, key string) string {
+	segments := strings.Split(fmt.Sprintf("%s/%s", bucket, key), "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Tags are sent as a url encoded query string on copy and put requests
+func EncodeTags(tags map[string]string) string {
+	v := url.Values{}
+	for k, t := range tags {
+		v.Set(k, t)
+	}
+
+	return v.Encode()
+}
+
+func TagSetToMap(tagSet []*s3.Tag) map[string]string {
+	m := make(map[string]string, len(tagSet))
+	for _, t := range tagSet {
+		m[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+	}
+
+	return m
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestGetCopySource(t *testing.T) {
+	tests := []struct {
+		bucket, key string
+		want        string
+	}{
+		{"b", "a/b.txt", "b/a/b.txt"},
+		{"b", "a b+c.txt", "b/a%20b%2Bc.txt"},
+		{"b", "x/y?z#w&v=1", "b/x/y%3Fz%23w%26v%3D1"},
+		{"b", "ünï/cödé", "b/%C3%BCn%C3%AF/c%C3%B6d%C3%A9"},
+		{"b", "dir/", "b/dir/"},
+	}
+
+	for _, tt := range tests {
+		if got := getCopySource(tt.bucket, tt.key); got != tt.want {
+			t.Errorf("getCopySource(%q, %q) = %q, want %q", tt.bucket, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParseS3Uri(t *testing.T) {
+	tests := []struct {
+		uri            string
+		bucket, prefix string
+		isErr          bool
+	}{
+		{"s3://b/some/prefix/", "b", "some/prefix/", false},
+		{"s3://b", "b", "", false},
+		{"s3:///key", "", "", true},
+		{"b/key", "", "", true},
+	}
+
+	for _, tt := range tests {
+		bucket, prefix, err := ParseS3Uri(tt.uri)
+		if (err != nil) != tt.isErr || bucket != tt.bucket || prefix != tt.prefix {
+			t.Errorf("ParseS3Uri(%q) = %q, %q, %v", tt.uri, bucket, prefix, err)
+		}
+	}
+}
+
+func TestGetCopyAttributes(t *testing.T) {
+	kms := &s3.HeadObjectOutput{
+		StorageClass:         aws.String(s3.StorageClassStandardIa),
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+		SSEKMSKeyId:          aws.String("key"),
+	}
+
+	tests := []struct {
+		name               string
+		head               *s3.HeadObjectOutput
+		opts               CopyOptions
+		class, sse, kmsKey string
+	}{
+		{"keeps the source", kms, CopyOptions{}, "STANDARD_IA", "aws:kms", "key"},
+		{"standard source", &s3.HeadObjectOutput{}, CopyOptions{}, "", "", ""},
+		{"new class", kms, CopyOptions{StorageClass: "GLACIER"}, "GLACIER", "aws:kms", "key"},
+		{"new encryption", kms, CopyOptions{ServerSideEncryption: aws.String("AES256")}, "STANDARD_IA", "AES256", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, sse, kmsKey := getCopyAttributes(tt.head, tt.opts)
+			if aws.StringValue(class) != tt.class || aws.StringValue(sse) != tt.sse || aws.StringValue(kmsKey) != tt.kmsKey {
+				t.Errorf("got %q, %q, %q", aws.StringValue(class), aws.StringValue(sse), aws.StringValue(kmsKey))
+			}
+		})
+	}
+}
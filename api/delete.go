@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DeleteObjects accepts at most this many keys per request
+const deleteBatchSize = 1000
+
+// A key that s3 refused to delete as part of a batch
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+func (e DeleteError) String() string {
+	return fmt.Sprintf("%s: %s (%s)", e.Key, e.Message, e.Code)
+}
+
+func DeleteObject(session *session.Session, bucket, key string) error {
+	client := s3.New(session)
+	_, err := client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+
+	return err
+}
+
+// Deletes the keys in batches of 1000.  A failed request stops the delete and is returned as err,
+// keys that s3 rejects inside an otherwise successful batch are collected and returned instead.
+// progress is called after every batch with the number of keys processed so far.
+func DeleteObjects(session *session.Session, bucket string, keys []string, progress func(done int)) ([]DeleteError, error) {
+	ids := make([]*s3.ObjectIdentifier, len(keys))
+	for i, k := range keys {
+		ids[i] = &s3.ObjectIdentifier{Key: aws.String(k)}
+	}
+
+	return deleteIdentifiers(s3.New(session), bucket, ids, progress)
+}
+
+func deleteIdentifiers(client *s3.S3, bucket string, ids []*s3.ObjectIdentifier, progress func(done int)) ([]DeleteError, error) {
+	failed := make([]DeleteError, 0)
+
+	for start := 0; start < len(ids); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		o, err := client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: &bucket,
+			Delete: &s3.Delete{
+				Objects: ids[start:end],
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return failed, err
+		}
+
+		for _, e := range o.Errors {
+			failed = append(failed, DeleteError{
+				Key:     aws.StringValue(e.Key),
+				Code:    aws.StringValue(e.Code),
+				Message: aws.StringValue(e.Message),
+			})
+		}
+
+		if progress != nil {
+			progress(end)
+		}
+	}
+
+	return failed, nil
+}
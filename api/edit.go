@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// An object downloaded to be edited, with everything a PUT has to repeat so only the body changes
+type EditableObject struct {
+	Body               []byte
+	ETag               string
+	ContentType        *string
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	ContentLanguage    *string
+	StorageClass       *string
+	Metadata           map[string]*string
+	Tags               map[string]string
+
+	ServerSideEncryption *string
+	SSEKMSKeyId          *string
+}
+
+func GetEditableObject(session *session.Session, bucket, key string) (*EditableObject, error) {
+	client := s3.New(session)
+	o, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer o.Body.Close()
+
+	body, err := io.ReadAll(o.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// A PUT drops the tags of the object it replaces
+	t, err := client.GetObjectTagging(&s3.GetObjectTaggingInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EditableObject{
+		Body:               body,
+		ETag:               aws.StringValue(o.ETag),
+		ContentType:        o.ContentType,
+		CacheControl:       o.CacheControl,
+		ContentDisposition: o.ContentDisposition,
+		ContentEncoding:    o.ContentEncoding,
+		ContentLanguage:    o.ContentLanguage,
+		StorageClass:       o.StorageClass,
+		Metadata:           o.Metadata,
+		Tags:               TagSetToMap(t.TagSet),
+
+		ServerSideEncryption: o.ServerSideEncryption,
+		SSEKMSKeyId:          o.SSEKMSKeyId,
+	}, nil
+}
+
+// Replaces the body of the object, keeping the content type, metadata, storage class, tags and
+// encryption it was downloaded with
+func PutEditedObject(session *session.Session, bucket, key string, body []byte, o *EditableObject) error {
+	client := s3.New(session)
+	input := s3.PutObjectInput{
+		Bucket:             &bucket,
+		Key:                &key,
+		Body:               bytes.NewReader(body),
+		ContentType:        o.ContentType,
+		CacheControl:       o.CacheControl,
+		ContentDisposition: o.ContentDisposition,
+		ContentEncoding:    o.ContentEncoding,
+		ContentLanguage:    o.ContentLanguage,
+		StorageClass:       o.StorageClass,
+		Metadata:           o.Metadata,
+
+		ServerSideEncryption: o.ServerSideEncryption,
+		SSEKMSKeyId:          o.SSEKMSKeyId,
+	}
+	if len(o.Tags) > 0 {
+		input.Tagging = aws.String(EncodeTags(o.Tags))
+	}
+
+	_, err := client.PutObject(&input)
+
+	return err
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// A named way of referring to a bucket or object, e.g. its ARN
+type Location struct {
+	Name  string
+	Value string
+}
+
+// Returns the S3 URI, ARN, HTTPS url and console link of the key.  An empty key refers to the bucket
+// itself.  The region and endpoint of the session are used, no request is made.
+func GetLocations(session *session.Session, bucket, key string) []Location {
+	region := aws.StringValue(session.Config.Region)
+	escapedKey := (&url.URL{Path: key}).EscapedPath()
+
+	httpsUrl := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, escapedKey)
+	if endpoint := aws.StringValue(session.Config.Endpoint); endpoint != "" {
+		// Custom endpoints (minio, localstack, ...) rarely support virtual hosted buckets
+		httpsUrl = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), bucket, escapedKey)
+	}
+
+	query := url.Values{}
+	query.Set("region", region)
+	console := fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s", bucket)
+	if key != "" {
+		query.Set("prefix", key)
+		if !strings.HasSuffix(key, "/") {
+			console = fmt.Sprintf("https://s3.console.aws.amazon.com/s3/object/%s", bucket)
+		}
+	}
+	console = fmt.Sprintf("%s?%s", console, query.Encode())
+
+	arn := fmt.Sprintf("arn:%s:s3:::%s", getPartition(region), bucket)
+	if key != "" {
+		arn = fmt.Sprintf("%s/%s", arn, key)
+	}
+
+	return []Location{
+		{Name: "S3 URI", Value: fmt.Sprintf("s3://%s/%s", bucket, key)},
+		{Name: "ARN", Value: arn},
+		{Name: "HTTPS URL", Value: httpsUrl},
+		{Name: "Console link", Value: console},
+	}
+}
+
+func getPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+
+	return "aws"
+}
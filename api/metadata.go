@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// System headers that can be changed on an object, in the order the form shows them
+var MetadataHeaders = []string{"Content-Type", "Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language"}
+
+// The metadata of an object along with what a copy onto itself needs to keep everything else
+type ObjectMetadata struct {
+	Key      string
+	ETag     string
+	Size     int64
+	Headers  map[string]string // System headers by name, missing when not set
+	Metadata map[string]string // User metadata without the x-amz-meta- prefix
+
+	storageClass         *string
+	serverSideEncryption *string
+	kmsKeyId             *string
+}
+
+func GetObjectMetadata(session *session.Session, bucket, key string) (*ObjectMetadata, error) {
+	client := s3.New(session)
+	o, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	for name, v := range map[string]*string{
+		"Content-Type":        o.ContentType,
+		"Cache-Control":       o.CacheControl,
+		"Content-Disposition": o.ContentDisposition,
+		"Content-Encoding":    o.ContentEncoding,
+		"Content-Language":    o.ContentLanguage,
+	} {
+		if aws.StringValue(v) != "" {
+			headers[name] = *v
+		}
+	}
+
+	return &ObjectMetadata{
+		Key:                  key,
+		ETag:                 aws.StringValue(o.ETag),
+		Size:                 aws.Int64Value(o.ContentLength),
+		Headers:              headers,
+		Metadata:             aws.StringValueMap(o.Metadata),
+		storageClass:         o.StorageClass,
+		serverSideEncryption: o.ServerSideEncryption,
+		kmsKeyId:             o.SSEKMSKeyId,
+	}, nil
+}
+
+// Metadata can only be changed by copying the object onto itself with MetadataDirective=REPLACE.  The
+// storage class and encryption are repeated so they are not reset, tags are copied along.  The copy
+// fails when the object changed since current was read.
+func ReplaceObjectMetadata(session *session.Session, bucket string, current *ObjectMetadata, headers, metadata map[string]string) error {
+	if current.Size > maxCopyObjectSize {
+		return fmt.Errorf("objects over 5 GB can not be copied onto themselves")
+	}
+
+	client := s3.New(session)
+	input := s3.CopyObjectInput{
+		Bucket:               &bucket,
+		Key:                  &current.Key,
+		CopySource:           aws.String(getCopySource(bucket, current.Key)),
+		CopySourceIfMatch:    &current.ETag,
+		MetadataDirective:    aws.String(s3.MetadataDirectiveReplace),
+		Metadata:             aws.StringMap(metadata),
+		StorageClass:         current.storageClass,
+		ServerSideEncryption: current.serverSideEncryption,
+		SSEKMSKeyId:          current.kmsKeyId,
+	}
+
+	for name, v := range headers {
+		if v == "" {
+			continue
+		}
+		switch name {
+		case "Content-Type":
+			input.ContentType = aws.String(v)
+		case "Cache-Control":
+			input.CacheControl = aws.String(v)
+		case "Content-Disposition":
+			input.ContentDisposition = aws.String(v)
+		case "Content-Encoding":
+			input.ContentEncoding = aws.String(v)
+		case "Content-Language":
+			input.ContentLanguage = aws.String(v)
+		}
+	}
+
+	_, err := client.CopyObject(&input)
+
+	return err
+}
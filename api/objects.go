@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
@@ -33,3 +34,39 @@ func GetObjects(session *session.Session, bucket, directory, filter string, cont
 
 	return o, nil
 }
+
+// Lists every object under prefix, following continuation tokens and without a delimiter so nested
+// "directories" are included.  Used by the recursive operations (delete, copy, ...) and their dry runs.
+func GetAllObjects(session *session.Session, bucket, prefix string) ([]*s3.Object, error) {
+	client := s3.New(session)
+	input := s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	}
+
+	objects := make([]*s3.Object, 0)
+	err := client.ListObjectsV2Pages(&input, func(o *s3.ListObjectsV2Output, lastPage bool) bool {
+		objects = append(objects, o.Contents...)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// HEADs the key, a missing object is not an error
+func ObjectExists(session *session.Session, bucket, key string) (bool, error) {
+	client := s3.New(session)
+	_, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// SigV4 presigned requests can not be valid for longer than a week
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// Everything a browser form or curl needs to upload with a POST policy
+type PresignedPost struct {
+	Url    string
+	Fields map[string]string
+}
+
+// Presigns a GET for the object.  versionId and contentDisposition are optional, the latter
+// overrides the Content-Disposition header of the response (e.g. to force a download filename).
+func PresignGet(session *session.Session, bucket, key, versionId, contentDisposition string, expiry time.Duration) (string, error) {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	input := s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	if versionId != "" {
+		input.VersionId = &versionId
+	}
+	if contentDisposition != "" {
+		input.ResponseContentDisposition = &contentDisposition
+	}
+
+	req, _ := client.GetObjectRequest(&input)
+
+	return req.Presign(expiry)
+}
+
+// Presigns a PUT so someone without credentials can upload to key.  When contentType is set the
+// uploader must send the same Content-Type header.
+func PresignPut(session *session.Session, bucket, key, contentType string, expiry time.Duration) (string, error) {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	input := s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	if contentType != "" {
+		input.ContentType = &contentType
+	}
+
+	req, _ := client.PutObjectRequest(&input)
+
+	return req.Presign(expiry)
+}
+
+// Builds a SigV4 POST policy allowing uploads of any key starting with keyPrefix.  The SDK has no
+// helper for this so the policy document is signed by hand.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
+func PresignPost(session *session.Session, bucket, keyPrefix string, expiry time.Duration) (*PresignedPost, error) {
+	client, err := getBucketClient(session, bucket)
+	if err != nil {
+		return nil, err
+	}
+	region := aws.StringValue(client.Config.Region)
+
+	postUrl, err := getPostUrl(client, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := session.Config.Credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	date := now.Format("20060102")
+	credential := fmt.Sprintf("%s/%s/%s/s3/aws4_request", creds.AccessKeyID, date, region)
+
+	fields := map[string]string{
+		"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+		"x-amz-credential": credential,
+		"x-amz-date":       now.Format("20060102T150405Z"),
+	}
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+
+	conditions := []interface{}{
+		map[string]string{"bucket": bucket},
+		[]string{"starts-with", "$key", keyPrefix},
+	}
+	for k, v := range fields {
+		conditions = append(conditions, map[string]string{k: v})
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": now.Add(expiry).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(policy)
+	key := hmacSha256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+
+	fields["policy"] = encoded
+	fields["x-amz-signature"] = hex.EncodeToString(hmacSha256(key, encoded))
+	fields["key"] = keyPrefix + "${filename}"
+
+	return &PresignedPost{
+		Url:    postUrl,
+		Fields: fields,
+	}, nil
+}
+
+// The form is posted to the bucket itself.  The SDK resolves it so custom endpoints, path style and
+// FIPS endpoints give the same url as the other presigned requests.
+func getPostUrl(client *s3.S3, bucket string) (string, error) {
+	req, _ := client.HeadBucketRequest(&s3.HeadBucketInput{Bucket: &bucket})
+	if err := req.Build(); err != nil {
+		return "", err
+	}
+
+	u := *req.HTTPRequest.URL
+	u.RawQuery = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+
+	return u.String(), nil
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestGetPostUrl(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *aws.Config
+		want   string
+	}{
+		{"virtual hosted", &aws.Config{Region: aws.String("eu-west-1")}, "https://my-bucket.s3.eu-west-1.amazonaws.com/"},
+		{"path style", &aws.Config{Region: aws.String("eu-west-1"), S3ForcePathStyle: aws.Bool(true)}, "https://s3.eu-west-1.amazonaws.com/my-bucket/"},
+		{
+			"custom endpoint",
+			&aws.Config{Region: aws.String("us-east-1"), Endpoint: aws.String("http://localhost:9000"), S3ForcePathStyle: aws.Bool(true)},
+			"http://localhost:9000/my-bucket/",
+		},
+		{"fips", &aws.Config{Region: aws.String("us-east-1"), UseFIPSEndpoint: endpoints.FIPSEndpointStateEnabled}, "https://my-bucket.s3-fips.us-east-1.amazonaws.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Credentials = credentials.NewStaticCredentials("id", "secret", "")
+			got, err := getPostUrl(s3.New(session.Must(session.NewSession(tt.config))), "my-bucket")
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"bytes"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Uploads body as a single request.  An empty contentType lets s3 fall back to its default.
+func PutObject(session *session.Session, bucket, key string, body []byte, contentType string) error {
+	client := s3.New(session)
+	input := s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Body:   bytes.NewReader(body),
+	}
+	if contentType != "" {
+		input.ContentType = &contentType
+	}
+
+	_, err := client.PutObject(&input)
+
+	return err
+}
+
+// Folders in s3 are only a convention, an empty object whose key ends with a slash makes the prefix
+// show up in listings even when nothing is stored under it yet.
+func CreateFolder(session *session.Session, bucket, key string) error {
+	return PutObject(session, bucket, key, nil, "")
+}
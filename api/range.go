@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// What HeadObject knows about an object, the previews use it to decide how to render the object
+type ObjectInfo struct {
+	Size            int64
+	ContentType     string
+	ContentEncoding string
+	ETag            string
+	LastModified    time.Time
+	Metadata        map[string]*string
+}
+
+func GetObjectInfo(session *session.Session, bucket, key string) (*ObjectInfo, error) {
+	client := s3.New(session)
+	o, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Size:            aws.Int64Value(o.ContentLength),
+		ContentType:     aws.StringValue(o.ContentType),
+		ContentEncoding: aws.StringValue(o.ContentEncoding),
+		ETag:            aws.StringValue(o.ETag),
+		LastModified:    aws.TimeValue(o.LastModified),
+		Metadata:        o.Metadata,
+	}, nil
+}
+
+// Reads the whole object, only for objects small enough to be held in memory
+func GetObject(session *session.Session, bucket, key string) ([]byte, error) {
+	client := s3.New(session)
+	o, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer o.Body.Close()
+
+	return io.ReadAll(o.Body)
+}
+
+// Reads the bytes from start to end inclusive.  Asking for more than is left returns what is left,
+// so callers can read in fixed size chunks without knowing the size of the object.
+func GetObjectRange(session *session.Session, bucket, key string, start, end int64) ([]byte, error) {
+	client := s3.New(session)
+	o, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer o.Body.Close()
+
+	return io.ReadAll(o.Body)
+}
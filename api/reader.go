@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const (
+	// Bytes fetched by every request of an ObjectReader
+	readAheadSize = 1024 * 1024
+
+	// Larger reads are split into several requests
+	maxRangeSize = 8 * 1024 * 1024
+)
+
+// Random access to an object through ranged GETs, for formats that keep their index somewhere in the
+// file (Parquet footers, zip directories, ...).  Every request reads ahead so the many small reads
+// decoders make do not each turn into a request.
+type ObjectReader struct {
+	session  *session.Session
+	bucket   string
+	key      string
+	size     int64
+	offset   int64
+	buf      []byte
+	bufStart int64
+}
+
+func NewObjectReader(session *session.Session, bucket, key string, size int64) *ObjectReader {
+	return &ObjectReader{
+		session: session,
+		bucket:  bucket,
+		key:     key,
+		size:    size,
+	}
+}
+
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+
+		if err := r.fill(pos, int64(len(p)-n)); err != nil {
+			return n, err
+		}
+		n += copy(p[n:], r.buf[pos-r.bufStart:])
+	}
+
+	return n, nil
+}
+
+// Hands out at most what one request returned.  Readers that buffer a whole range up front, like the
+// column chunks of the parquet library, then only download the part they decode.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if err := r.fill(r.offset, int64(len(p))); err != nil {
+		return 0, err
+	}
+	n := copy(p, r.buf[r.offset-r.bufStart:])
+	r.offset += int64(n)
+
+	return n, nil
+}
+
+// Makes sure the buffer holds pos, fetching at least readAheadSize and at most maxRangeSize bytes
+func (r *ObjectReader) fill(pos, want int64) error {
+	if pos >= r.bufStart && pos < r.bufStart+int64(len(r.buf)) {
+		return nil
+	}
+
+	if want < readAheadSize {
+		want = readAheadSize
+	}
+	if want > maxRangeSize {
+		want = maxRangeSize
+	}
+	end := pos + want
+	if end > r.size {
+		end = r.size
+	}
+
+	b, err := GetObjectRange(r.session, r.bucket, r.key, pos, end-1)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	r.buf = b
+	r.bufStart = pos
+
+	return nil
+}
+
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+
+	return offset, nil
+}
+
+// A second reader of the same object with its own position and buffer
+func (r *ObjectReader) Clone() *ObjectReader {
+	return NewObjectReader(r.session, r.bucket, r.key, r.size)
+}
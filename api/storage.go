@@ -0,0 +1,107 @@
+package api
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Storage classes an object can be moved to, cheapest to read first
+var StorageClasses = []string{
+	s3.StorageClassStandard,
+	s3.StorageClassIntelligentTiering,
+	s3.StorageClassStandardIa,
+	s3.StorageClassOnezoneIa,
+	s3.StorageClassGlacierIr,
+	s3.StorageClassGlacier,
+	s3.StorageClassDeepArchive,
+}
+
+// Tiers of a restore, Expedited is not available for DEEP_ARCHIVE
+var RestoreTiers = []string{s3.TierStandard, s3.TierBulk, s3.TierExpedited}
+
+var (
+	restoreOngoing = regexp.MustCompile(`ongoing-request="true"`)
+	restoreExpiry  = regexp.MustCompile(`expiry-date="([^"]+)"`)
+)
+
+// Objects in these classes have to be restored before they can be read
+func IsArchived(storageClass string) bool {
+	return storageClass == s3.StorageClassGlacier || storageClass == s3.StorageClassDeepArchive
+}
+
+// State of a restore from the Restore header of HeadObject.  Expiry is set once the restored copy is
+// available.
+type RestoreStatus struct {
+	Ongoing bool
+	Expiry  time.Time
+}
+
+// Returns nil when the object was never restored or the restored copy expired
+func GetRestoreStatus(session *session.Session, bucket, key string) (*RestoreStatus, error) {
+	client := s3.New(session)
+	o, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRestoreHeader(aws.StringValue(o.Restore)), nil
+}
+
+// The header looks like ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+func ParseRestoreHeader(h string) *RestoreStatus {
+	if h == "" {
+		return nil
+	}
+
+	s := &RestoreStatus{Ongoing: restoreOngoing.MatchString(h)}
+	if m := restoreExpiry.FindStringSubmatch(h); m != nil {
+		s.Expiry, _ = time.Parse(time.RFC1123, m[1])
+	}
+
+	return s
+}
+
+// A temporary copy of an archived object is made readable for the given number of days
+func RestoreObject(session *session.Session, bucket, key, tier string, days int64) error {
+	client := s3.New(session)
+	_, err := client.RestoreObject(&s3.RestoreObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		RestoreRequest: &s3.RestoreRequest{
+			Days:                 aws.Int64(days),
+			GlacierJobParameters: &s3.GlacierJobParameters{Tier: aws.String(tier)},
+		},
+	})
+
+	return err
+}
+
+// Copies the object onto itself in the new class, metadata, tags and encryption are kept
+func ChangeStorageClass(session *session.Session, bucket, key string, size int64, storageClass string) error {
+	o, err := GetObjectMetadata(session, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	item := CopyItem{
+		SourceBucket: bucket,
+		SourceKey:    key,
+		Size:         size,
+		DestBucket:   bucket,
+		DestKey:      key,
+	}
+	opts := CopyOptions{
+		StorageClass:         storageClass,
+		ServerSideEncryption: o.serverSideEncryption,
+		SSEKMSKeyId:          o.kmsKeyId,
+	}
+
+	return CopyObject(session, item, opts, nil)
+}
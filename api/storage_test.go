@@ -0,0 +1,41 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRestoreHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *RestoreStatus
+	}{
+		{"", nil},
+		{`ongoing-request="true"`, &RestoreStatus{Ongoing: true}},
+		{
+			`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`,
+			&RestoreStatus{Expiry: time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)},
+		},
+		{`ongoing-request="false", expiry-date="tomorrow"`, &RestoreStatus{}},
+	}
+
+	for _, tt := range tests {
+		got := ParseRestoreHeader(tt.header)
+		if got != nil && tt.want != nil && got.Expiry.Equal(tt.want.Expiry) {
+			got.Expiry = tt.want.Expiry
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRestoreHeader(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestIsArchived(t *testing.T) {
+	for _, c := range StorageClasses {
+		want := c == "GLACIER" || c == "DEEP_ARCHIVE"
+		if IsArchived(c) != want {
+			t.Errorf("IsArchived(%q) = %v", c, !want)
+		}
+	}
+}
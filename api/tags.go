@@ -0,0 +1,56 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3 does not allow more tags than this on an object
+const MaxObjectTags = 10
+
+func GetObjectTags(session *session.Session, bucket, key string) (map[string]string, error) {
+	client := s3.New(session)
+	t, err := client.GetObjectTagging(&s3.GetObjectTaggingInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return TagSetToMap(t.TagSet), nil
+}
+
+// Replaces every tag of the object, an empty map removes them all
+func PutObjectTags(session *session.Session, bucket, key string, tags map[string]string) error {
+	client := s3.New(session)
+	if len(tags) == 0 {
+		_, err := client.DeleteObjectTagging(&s3.DeleteObjectTaggingInput{
+			Bucket: &bucket,
+			Key:    &key,
+		})
+		return err
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]*s3.Tag, len(keys))
+	for i, k := range keys {
+		tagSet[i] = &s3.Tag{Key: aws.String(k), Value: aws.String(tags[k])}
+	}
+
+	_, err := client.PutObjectTagging(&s3.PutObjectTaggingInput{
+		Bucket:  &bucket,
+		Key:     &key,
+		Tagging: &s3.Tagging{TagSet: tagSet},
+	})
+
+	return err
+}
@@ -5,8 +5,10 @@ import (
 	"s3-viewer/api"
 	"s3-viewer/ui/components/dialog"
 	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/prompt"
 	spin "s3-viewer/ui/components/spinner"
 	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
 	"s3-viewer/ui/types"
 	"time"
 
@@ -25,10 +27,16 @@ var (
 )
 
 type bucketsModel struct {
-	buckets   []*s3.Bucket
-	spinner   spinner.Model
-	isLoading bool
-	table     *table.Model
+	buckets        []*s3.Bucket
+	spinner        spinner.Model
+	isLoading      bool
+	table          *table.Model
+	loadingMessage string // Shown instead of the table while a background request runs
+	prompt         *prompt.Model
+	progress       *task.ProgressMsg
+	pendingCreate  *api.BucketOptions
+	pendingDelete  *pendingBucketDelete
+	locations      []api.Location
 }
 
 type getBucketsMsg struct {
@@ -59,17 +67,34 @@ func Init(m *types.UiModel) tea.Cmd {
 
 	cmds := make([]tea.Cmd, 0)
 	cmds = append(cmds, model.spinner.Tick)
+	cmds = append(cmds, createGetBucketsMsg(m))
 
-	cmds = append(cmds, func() tea.Msg {
+	return tea.Batch(cmds...)
+}
+
+func createGetBucketsMsg(m *types.UiModel) tea.Cmd {
+	return func() tea.Msg {
 		b, err := api.GetBuckets(m.Session)
 		model.isLoading = false
 		if err != nil {
 			return getBucketsMsg{nil, err}
 		}
 		return getBucketsMsg{b, nil}
-	})
+	}
+}
 
-	return tea.Batch(cmds...)
+func openPrompt(p *prompt.Model, cmds *[]tea.Cmd) {
+	model.prompt = p
+	*cmds = append(*cmds, p.Init())
+}
+
+func closePrompt() {
+	model.prompt = nil
+}
+
+func showLoading(msg string, cmds *[]tea.Cmd) {
+	model.loadingMessage = msg
+	*cmds = append(*cmds, model.spinner.Tick)
 }
 
 func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
@@ -81,6 +106,7 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 			panic(msg.err) //TODO do something actually meaningful here
 		}
 
+		model.loadingMessage = ""
 		model.buckets = msg.buckets
 		r := make([]table.Row, 0)
 		for _, b := range model.buckets {
@@ -88,7 +114,37 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 		}
 		model.table.SetData(r)
 
+	case prompt.SubmittedMsg:
+		handlePromptSubmittedMsg(m, msg, &cmds)
+
+	case prompt.CancelledMsg:
+		model.pendingCreate = nil
+		model.pendingDelete = nil
+		closePrompt()
+
+	case deleteBucketDryRunMsg:
+		handleDeleteBucketDryRunMsg(m, msg, &cmds)
+
+	case task.ProgressMsg:
+		model.progress = &msg
+		cmds = append(cmds, msg.Next())
+
+	case task.DoneMsg:
+		handleTaskDoneMsg(m, msg, &cmds)
+
 	case tea.KeyMsg:
+		// A prompt is open so it receives all of the keys
+		if model.prompt != nil {
+			var cmd tea.Cmd
+			model.prompt, cmd = model.prompt.Update(msg)
+			return cmd
+		}
+
+		// Ignore keys while a background task or request is running
+		if model.progress != nil || model.loadingMessage != "" {
+			return nil
+		}
+
 		switch msg.String() {
 		// 	case "esc":
 		// 		if model.table.Focused() {
@@ -101,6 +157,15 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 			if r != nil {
 				cmds = append(cmds, m.SetCurrentPage(types.Files, &(*r)[1]))
 			}
+
+		case "n":
+			handleCreateBucketKeyMsg(m, &cmds)
+
+		case "d":
+			handleDeleteBucketKeyMsg(m, &cmds)
+
+		case "y":
+			handleCopyLocationKeyMsg(m, &cmds)
 		}
 
 		var cmd tea.Cmd
@@ -108,7 +173,14 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 		cmds = append(cmds, cmd)
 	}
 
-	if model.isLoading {
+	// Forward the rest of the messages (cursor blink) to an open prompt
+	if _, ok := msg.(tea.KeyMsg); !ok && model.prompt != nil {
+		var pc tea.Cmd
+		model.prompt, pc = model.prompt.Update(msg)
+		cmds = append(cmds, pc)
+	}
+
+	if model.isLoading || model.loadingMessage != "" {
 		var sc tea.Cmd
 		model.spinner, sc = model.spinner.Update(msg)
 		cmds = append(cmds, sc)
@@ -122,6 +194,18 @@ func View(m *types.UiModel) string {
 		return dialog.GetLoadingDialog("Loading Buckets", model.spinner)
 	}
 
+	if model.prompt != nil {
+		return model.prompt.ViewPlaced()
+	}
+
+	if model.progress != nil {
+		return dialog.GetProgressDialog(model.progress.Message, model.progress.Percent())
+	}
+
+	if model.loadingMessage != "" {
+		return dialog.GetLoadingDialog(model.loadingMessage, model.spinner)
+	}
+
 	if model.buckets != nil {
 		// Get terminal size and place dialog in the center
 		docStyle := lipgloss.NewStyle()
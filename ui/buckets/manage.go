@@ -0,0 +1,229 @@
+package buckets
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/location"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	createBucketPrompt        = "create-bucket"
+	createBucketConfirmPrompt = "create-bucket-confirm"
+	deleteBucketPrompt        = "delete-bucket"
+	createBucketTask          = "create-bucket"
+	deleteBucketTask          = "delete-bucket"
+)
+
+type pendingBucketDelete struct {
+	bucket     string
+	emptyFirst bool
+}
+
+type deleteBucketDryRunMsg struct {
+	bucket string
+	count  int
+	size   int64
+	err    error
+}
+
+type deleteBucketResult struct {
+	failed []api.DeleteError
+}
+
+func handleCreateBucketKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	fields := []prompt.Field{
+		{Label: "Name", Placeholder: "my-bucket"},
+		{Label: "Region", Placeholder: "us-east-1", Value: aws.StringValue(m.Session.Config.Region)},
+		{Label: "Object ownership", Options: s3.ObjectOwnership_Values(), Value: s3.ObjectOwnershipBucketOwnerEnforced},
+		{Label: "Block public access", Options: []string{"On", "Off"}},
+		{Label: "Versioning", Options: []string{"Disabled", "Enabled"}},
+		{Label: "Encryption", Options: []string{s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms}},
+		{Label: "KMS key id", Placeholder: "aws managed key"},
+	}
+
+	openPrompt(prompt.New(createBucketPrompt, "Create bucket", "", fields, nil), cmds)
+}
+
+// The form is validated first and then a summary of the bucket is shown for a final confirmation
+func handleCreateBucketSubmitted(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	opts := api.BucketOptions{
+		Name:              strings.TrimSpace(msg.Values[0]),
+		Region:            strings.TrimSpace(msg.Values[1]),
+		ObjectOwnership:   msg.Values[2],
+		BlockPublicAccess: msg.Values[3] == "On",
+		Versioning:        msg.Values[4] == "Enabled",
+		Encryption:        msg.Values[5],
+		KmsKeyId:          strings.TrimSpace(msg.Values[6]),
+	}
+
+	if err := api.ValidateBucketName(opts.Name); err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+	if opts.Region == "" {
+		model.prompt.SetError("enter a region")
+		return
+	}
+	if opts.KmsKeyId != "" && opts.Encryption != s3.ServerSideEncryptionAwsKms {
+		model.prompt.SetError("a KMS key can only be used with aws:kms encryption")
+		return
+	}
+
+	model.pendingCreate = &opts
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Name:                %s\n", opts.Name)
+	fmt.Fprintf(&b, "Region:              %s\n", opts.Region)
+	fmt.Fprintf(&b, "Object ownership:    %s\n", opts.ObjectOwnership)
+	fmt.Fprintf(&b, "Block public access: %s\n", msg.Values[3])
+	fmt.Fprintf(&b, "Versioning:          %s\n", msg.Values[4])
+	fmt.Fprintf(&b, "Encryption:          %s", opts.Encryption)
+	if opts.KmsKeyId != "" {
+		fmt.Fprintf(&b, " (%s)", opts.KmsKeyId)
+	}
+	if !opts.BlockPublicAccess {
+		b.WriteString("\n\n⚠ Public access will not be blocked, objects can be made public.")
+	}
+
+	openPrompt(prompt.NewConfirm(createBucketConfirmPrompt, "Create bucket?", b.String(), "Create"), cmds)
+}
+
+func handleCreateBucketConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	opts := model.pendingCreate
+	model.pendingCreate = nil
+	closePrompt()
+
+	if opts == nil || msg.Option != 0 {
+		return
+	}
+
+	*cmds = append(*cmds, task.Run(createBucketTask, func(report task.Reporter) (interface{}, error) {
+		report(0, 1, fmt.Sprintf("Creating %s in %s", opts.Name, opts.Region))
+		return nil, api.CreateBucket(m.Session, *opts)
+	}))
+}
+
+func handleDeleteBucketKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil {
+		return
+	}
+
+	bucket := (*r)[1]
+	showLoading(fmt.Sprintf("Counting objects in %s", bucket), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		c, s, err := api.CountObjectVersions(m.Session, bucket)
+		return deleteBucketDryRunMsg{bucket, c, s, err}
+	})
+}
+
+func handleDeleteBucketDryRunMsg(m *types.UiModel, msg deleteBucketDryRunMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Delete bucket failed", msg.err.Error()), cmds)
+		return
+	}
+
+	model.pendingDelete = &pendingBucketDelete{bucket: msg.bucket}
+
+	body := fmt.Sprintf("s3://%s is empty and will be permanently deleted.", msg.bucket)
+	if msg.count > 0 {
+		body = fmt.Sprintf(
+			"s3://%s contains %v objects and versions (%s). A bucket must be empty before it can be deleted, choose \"Empty first\" to remove all of them.",
+			msg.bucket,
+			msg.count,
+			utils.GetFriendlyByteDisplay(msg.size))
+	}
+	body += "\n\nType the bucket name to confirm:"
+
+	fields := []prompt.Field{
+		{Label: "Empty first", Options: []string{"No", "Yes"}},
+		{Label: "Bucket name", Placeholder: msg.bucket},
+	}
+	openPrompt(prompt.New(deleteBucketPrompt, "Delete bucket", body, fields, nil), cmds)
+}
+
+func handleDeleteBucketConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pd := model.pendingDelete
+	if pd == nil {
+		closePrompt()
+		return
+	}
+
+	if strings.TrimSpace(msg.Values[1]) != pd.bucket {
+		model.prompt.SetError("the typed name does not match the bucket")
+		return
+	}
+	pd.emptyFirst = msg.Values[0] == "Yes"
+
+	closePrompt()
+	model.pendingDelete = nil
+
+	*cmds = append(*cmds, task.Run(deleteBucketTask, func(report task.Reporter) (interface{}, error) {
+		result := deleteBucketResult{}
+
+		if pd.emptyFirst {
+			report(0, 0, fmt.Sprintf("Emptying %s", pd.bucket))
+			failed, err := api.EmptyBucket(m.Session, pd.bucket, func(done int) {
+				report(0, 0, fmt.Sprintf("Emptying %s, %v objects and versions removed", pd.bucket, done))
+			})
+			result.failed = failed
+			if err != nil || len(failed) > 0 {
+				return result, err
+			}
+		}
+
+		report(1, 1, fmt.Sprintf("Deleting %s", pd.bucket))
+		return result, api.DeleteBucket(m.Session, pd.bucket)
+	}))
+}
+
+func handleTaskDoneMsg(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	showLoading("Loading Buckets", cmds)
+	*cmds = append(*cmds, createGetBucketsMsg(m))
+
+	if msg.Err != nil {
+		title := "Create bucket failed"
+		if msg.Id == deleteBucketTask {
+			title = "Delete bucket failed"
+		}
+		openPrompt(prompt.NewMessage("", title, msg.Err.Error()), cmds)
+		return
+	}
+
+	if r, ok := msg.Result.(deleteBucketResult); ok && len(r.failed) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%v objects or versions could not be removed so the bucket was kept:\n", len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Delete bucket failed", b.String()), cmds)
+	}
+}
+
+func handlePromptSubmittedMsg(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	switch msg.Id {
+	case createBucketPrompt:
+		handleCreateBucketSubmitted(m, msg, cmds)
+
+	case createBucketConfirmPrompt:
+		handleCreateBucketConfirmed(m, msg, cmds)
+
+	case deleteBucketPrompt:
+		handleDeleteBucketConfirmed(m, msg, cmds)
+
+	case location.PromptId:
+		handleCopyLocationConfirmed(m, msg, cmds)
+
+	default:
+		closePrompt()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/term"
@@ -11,17 +12,36 @@ import (
 
 var (
 	DialogBoxStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#874BFD")).
-		Padding(1, 0).
-		BorderTop(true).
-		BorderLeft(true).
-		BorderRight(true).
-		BorderBottom(true)
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#874BFD")).
+			Padding(1, 0).
+			BorderTop(true).
+			BorderLeft(true).
+			BorderRight(true).
+			BorderBottom(true)
+
+	progressTextStyle = lipgloss.NewStyle().Width(50).Align(lipgloss.Center)
 )
 
 func GetLoadingDialog(msg string, s spinner.Model) string {
-	// Get terminal size and place dialog in the center
+	return PlaceDialog(DialogBoxStyle.Render(fmt.Sprintf("%s%s", s.View(), msg)))
+}
+
+// Renders a static progress bar with the given message above it.  percent is between 0 and 1.
+func GetProgressDialog(msg string, percent float64) string {
+	p := progress.New(progress.WithDefaultGradient(), progress.WithWidth(46))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		progressTextStyle.Render(msg),
+		"",
+		p.ViewAs(percent))
+
+	return PlaceDialog(DialogBoxStyle.Copy().Padding(1, 2).Render(content))
+}
+
+// Get terminal size and place the already rendered dialog in the center
+func PlaceDialog(d string) string {
 	docStyle := lipgloss.NewStyle()
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
 
@@ -35,7 +55,7 @@ func GetLoadingDialog(msg string, s spinner.Model) string {
 	p := lipgloss.Place(
 		width, height,
 		lipgloss.Center, lipgloss.Center,
-		DialogBoxStyle.Render(fmt.Sprintf("%s%s", s.View(), msg)),
+		d,
 		lipgloss.WithWhitespaceChars("Ш#"),
 		lipgloss.WithWhitespaceForeground(lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"}))
 
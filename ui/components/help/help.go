@@ -24,6 +24,9 @@ func GetBucketsHelp() string {
 		{key: "\u2191", desc: "up"},
 		{key: "\u2193", desc: "down"},
 		{key: "enter", desc: "open folder"},
+		{key: "n", desc: "new bucket"},
+		{key: "d", desc: "delete bucket"},
+		{key: "y", desc: "copy location"},
 		{key: "ctrl + c", desc: "quit"},
 	}
 
@@ -35,8 +38,24 @@ func GetFilesHelp(filterPromptVisible bool, currentFilter string) string {
 	if !filterPromptVisible {
 		items = append(items, helpItem{key: "\u2191", desc: "up"})
 		items = append(items, helpItem{key: "\u2193", desc: "down"})
-		items = append(items, helpItem{key: "enter", desc: "open folder"})
+		items = append(items, helpItem{key: "enter", desc: "open"})
 		items = append(items, helpItem{key: "/", desc: "filter"})
+		items = append(items, helpItem{key: "space", desc: "select"})
+		items = append(items, helpItem{key: "d", desc: "delete"})
+		items = append(items, helpItem{key: "c", desc: "copy"})
+		items = append(items, helpItem{key: "m", desc: "move"})
+		items = append(items, helpItem{key: "r", desc: "rename"})
+		items = append(items, helpItem{key: "R", desc: "regex rename"})
+		items = append(items, helpItem{key: "n", desc: "new folder"})
+		items = append(items, helpItem{key: "N", desc: "new file"})
+		items = append(items, helpItem{key: "e", desc: "edit"})
+		items = append(items, helpItem{key: "M", desc: "metadata"})
+		items = append(items, helpItem{key: "t", desc: "tags"})
+		items = append(items, helpItem{key: "S", desc: "storage class"})
+		items = append(items, helpItem{key: "G", desc: "restore"})
+		items = append(items, helpItem{key: "s", desc: "share"})
+		items = append(items, helpItem{key: "y", desc: "copy location"})
+		items = append(items, helpItem{key: "L", desc: "analyze logs"})
 	}
 
 	if filterPromptVisible {
@@ -51,6 +70,270 @@ func GetFilesHelp(filterPromptVisible bool, currentFilter string) string {
 	return renderHelpItems(items)
 }
 
+func GetArchiveHelp(filterPromptVisible bool, currentFilter string) string {
+	items := make([]helpItem, 0)
+	if !filterPromptVisible {
+		items = append(items, helpItem{key: "\u2191", desc: "up"})
+		items = append(items, helpItem{key: "\u2193", desc: "down"})
+		items = append(items, helpItem{key: "enter", desc: "open"})
+		items = append(items, helpItem{key: "/", desc: "filter"})
+		items = append(items, helpItem{key: "D", desc: "download"})
+	}
+
+	if filterPromptVisible {
+		items = append(items, helpItem{key: "enter", desc: "apply filter"})
+		items = append(items, helpItem{key: "esc", desc: "exit filter"})
+	} else if currentFilter != "" {
+		items = append(items, helpItem{key: "esc", desc: "clear filter"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "up"})
+	}
+
+	items = append(items, helpItem{key: "ctrl + c", desc: "quit"})
+
+	return renderHelpItems(items)
+}
+
+func GetChangePreviewHelp() string {
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "enter", desc: "apply"},
+		{key: "esc", desc: "cancel"},
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetTagsHelp() string {
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "a", desc: "add"},
+		{key: "enter", desc: "edit"},
+		{key: "d", desc: "remove"},
+		{key: "esc", desc: "close"},
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetRenamePreviewHelp() string {
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "enter", desc: "rename"},
+		{key: "esc", desc: "cancel"},
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetTextPreviewHelp(searchPromptVisible bool, hasSearch bool, hasSyntax bool, canFollow bool) string {
+	if searchPromptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "search"},
+			{key: "esc", desc: "cancel"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "g/G", desc: "top/bottom"},
+		{key: "/", desc: "search"},
+	}
+	if hasSearch {
+		items = append(items, helpItem{key: "n/N", desc: "next/prev match"})
+	}
+	items = append(items, helpItem{key: "l", desc: "line numbers"})
+	items = append(items, helpItem{key: "w", desc: "wrap"})
+	if hasSyntax {
+		items = append(items, helpItem{key: "T", desc: "theme"})
+	}
+	if canFollow {
+		items = append(items, helpItem{key: "F", desc: "follow"})
+	}
+	if hasSearch {
+		items = append(items, helpItem{key: "esc", desc: "clear search"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "back"})
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetLogPreviewHelp(filterPromptVisible bool, currentFilter string, isCount bool) string {
+	if filterPromptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "apply filter"},
+			{key: "esc", desc: "exit filter"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "\u2190/\u2192", desc: "columns"},
+		{key: "tab", desc: "next tab"},
+		{key: "/", desc: "filter"},
+	}
+	if isCount {
+		items = append(items, helpItem{key: "enter", desc: "show requests"})
+	}
+	if currentFilter != "" {
+		items = append(items, helpItem{key: "esc", desc: "clear filter"})
+	} else if isCount {
+		items = append(items, helpItem{key: "esc", desc: "requests"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "back"})
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetCloudTrailPreviewHelp(filterPromptVisible bool, currentFilter string) string {
+	if filterPromptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "apply filter"},
+			{key: "esc", desc: "exit filter"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "\u2190/\u2192", desc: "columns"},
+		{key: "enter", desc: "detail"},
+		{key: "/", desc: "filter"},
+	}
+	if currentFilter != "" {
+		items = append(items, helpItem{key: "esc", desc: "clear filter"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "back"})
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetTreePreviewHelp(queryPromptVisible bool, hasQuery bool, hasRecords bool) string {
+	if queryPromptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "run query"},
+			{key: "esc", desc: "cancel"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "enter", desc: "expand/collapse"},
+		{key: "\u2190", desc: "parent"},
+		{key: "*", desc: "expand all"},
+		{key: ":", desc: "query"},
+		{key: "y", desc: "copy value"},
+	}
+	if hasRecords {
+		items = append(items, helpItem{key: "[/]", desc: "prev/next record"})
+	}
+	if hasQuery {
+		items = append(items, helpItem{key: "esc", desc: "clear query"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "back"})
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetCsvPreviewHelp(jumpPromptVisible bool) string {
+	if jumpPromptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "jump"},
+			{key: "esc", desc: "cancel"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "\u2190/\u2192", desc: "scroll columns"},
+		{key: "g/G", desc: "first/last row"},
+		{key: ":", desc: "jump to row"},
+		{key: "esc", desc: "back"},
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetParquetPreviewHelp() string {
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "\u2190/\u2192", desc: "scroll columns"},
+		{key: "tab", desc: "schema/row groups/rows"},
+		{key: "esc", desc: "back"},
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetHexPreviewHelp(promptVisible bool, hasSearch bool) string {
+	if promptVisible {
+		return renderHelpItems([]helpItem{
+			{key: "enter", desc: "ok"},
+			{key: "esc", desc: "cancel"},
+		})
+	}
+
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "g/G", desc: "start/end"},
+		{key: ":", desc: "jump to offset"},
+		{key: "/", desc: "search text"},
+		{key: "x", desc: "search bytes"},
+	}
+	if hasSearch {
+		items = append(items, helpItem{key: "n/N", desc: "next/prev match"})
+		items = append(items, helpItem{key: "esc", desc: "clear search"})
+	} else {
+		items = append(items, helpItem{key: "esc", desc: "back"})
+	}
+
+	return renderHelpItems(items)
+}
+
+func GetMarkdownPreviewHelp(raw bool) string {
+	items := []helpItem{
+		{key: "\u2191", desc: "up"},
+		{key: "\u2193", desc: "down"},
+		{key: "g/G", desc: "top/bottom"},
+	}
+	if raw {
+		items = append(items, helpItem{key: "/", desc: "search"})
+		items = append(items, helpItem{key: "m", desc: "rendered"})
+	} else {
+		items = append(items, helpItem{key: "m", desc: "raw source"})
+	}
+	items = append(items, helpItem{key: "esc", desc: "back"})
+
+	return renderHelpItems(items)
+}
+
+func GetImagePreviewHelp(canRedraw bool) string {
+	items := make([]helpItem, 0)
+	if canRedraw {
+		items = append(items, helpItem{key: "r", desc: "redraw"})
+	}
+	items = append(items, helpItem{key: "esc", desc: "back"})
+
+	return renderHelpItems(items)
+}
+
+func GetPreviewErrorHelp() string {
+	return renderHelpItems([]helpItem{{key: "esc", desc: "back"}})
+}
+
 func renderHelpItems(items []helpItem) string {
 	var s strings.Builder
 
@@ -4,6 +4,7 @@ package icons
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // Icon (icon information)
@@ -14,10 +15,13 @@ type Icon struct {
 }
 
 func GetIcon(file string) string {
-	ext := filepath.Ext(file)
+	ext := GetExtension(file)
+	if IsImage(file) {
+		return render(icons["image"])
+	}
 
-	if len(ext) > 1 {
-		i, ok := icons[ext[1:]]
+	if ext != "" {
+		i, ok := icons[ext]
 		if ok {
 			return render(i)
 		}
@@ -26,6 +30,32 @@ func GetIcon(file string) string {
 	return render(defaults["file"])
 }
 
+// The extension without the dot that icons (and the previews) are looked up by
+func GetExtension(file string) string {
+	ext := filepath.Ext(file)
+	if len(ext) > 1 {
+		return ext[1:]
+	}
+
+	return ""
+}
+
+// Extensions shown with the image icon, the preview renders them
+var imageExtensions = map[string]bool{
+	"png":  true,
+	"jpg":  true,
+	"jpeg": true,
+	"gif":  true,
+	"webp": true,
+	"bmp":  true,
+	"tif":  true,
+	"tiff": true,
+}
+
+func IsImage(file string) bool {
+	return imageExtensions[strings.ToLower(GetExtension(file))]
+}
+
 func GetDirectoryIcon() string {
 	return render(defaults["dir"])
 }
@@ -0,0 +1,30 @@
+package location
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/utils"
+)
+
+// Id of the prompt listing the locations, the page passes the submitted option back to Copy
+const PromptId = "copy-location"
+
+// Lists the ways to refer to a bucket or object, uri is shown as the body
+func NewPrompt(uri string, locations []api.Location) *prompt.Model {
+	options := make([]string, len(locations))
+	for i, l := range locations {
+		options[i] = l.Name
+	}
+
+	return prompt.New(PromptId, "Copy location", uri, nil, options)
+}
+
+// Copies the picked location to the clipboard and returns the message telling how it went
+func Copy(l api.Location) *prompt.Model {
+	if err := utils.CopyToClipboard(l.Value); err != nil {
+		return prompt.NewMessage("", "Copy failed", err.Error())
+	}
+
+	return prompt.NewMessage("", "Copied", fmt.Sprintf("%s copied to the clipboard:\n\n%s", l.Name, l.Value))
+}
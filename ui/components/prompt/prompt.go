@@ -0,0 +1,326 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"s3-viewer/ui/components/dialog"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	noStyle      = lipgloss.NewStyle()
+	titleStyle   = lipgloss.NewStyle().Bold(true).Width(60).Align(lipgloss.Center)
+	bodyStyle    = lipgloss.NewStyle().Width(60).Padding(1, 2, 0, 2)
+	labelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Width(22)
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff4754")).Width(60).Padding(1, 2, 0, 2)
+	hintStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#5e5e5e")).Width(60).Align(lipgloss.Center).MarginTop(1)
+
+	optionStyle = lipgloss.NewStyle().
+			Padding(0, 2)
+
+	activeOptionStyle = optionStyle.Copy().
+				Foreground(lipgloss.Color("#FFF7DB")).
+				Background(lipgloss.Color("#F25D94"))
+)
+
+// A single line of a prompt.  When Options is set the field is a choice that is cycled with the
+// left and right keys, otherwise it is a free text input.
+type Field struct {
+	Label       string
+	Placeholder string
+	Value       string
+	Options     []string
+}
+
+// Model is a small modal used by the pages for confirmations, questions and results.  The page owns
+// the model, forwards key presses to it while it is open and decides what to do with the values once
+// a SubmittedMsg comes back.
+type Model struct {
+	id         string
+	title      string
+	body       string
+	errMessage string
+
+	fields      []Field
+	inputs      []textinput.Model
+	choices     []int
+	focusIndex  int
+	options     []string
+	optionIndex int
+}
+
+// Sent when enter is pressed.  Values holds one entry per field and Option is the index of the
+// highlighted option (0 when the prompt has no options).
+type SubmittedMsg struct {
+	Id     string
+	Values []string
+	Option int
+}
+
+// Sent when the prompt is dismissed with esc
+type CancelledMsg struct {
+	Id string
+}
+
+func New(id, title, body string, fields []Field, options []string) *Model {
+	m := Model{
+		id:      id,
+		title:   title,
+		body:    body,
+		fields:  fields,
+		inputs:  make([]textinput.Model, len(fields)),
+		choices: make([]int, len(fields)),
+		options: options,
+	}
+
+	for i, f := range fields {
+		t := textinput.New()
+		t.Placeholder = f.Placeholder
+		t.CharLimit = 1024
+		t.Width = 34
+		t.CursorStyle = focusedStyle.Copy()
+		t.SetValue(f.Value)
+		m.inputs[i] = t
+
+		for j, o := range f.Options {
+			if o == f.Value {
+				m.choices[i] = j
+			}
+		}
+	}
+
+	m.setFocus(0)
+
+	return &m
+}
+
+// A prompt with only a body and an ok button
+func NewMessage(id, title, body string) *Model {
+	return New(id, title, body, nil, []string{"Ok"})
+}
+
+// A yes / no prompt.  Option 0 of the SubmittedMsg is the confirmation.
+func NewConfirm(id, title, body, action string) *Model {
+	m := New(id, title, body, nil, []string{action, "Cancel"})
+	m.optionIndex = 1
+
+	return m
+}
+
+// A prompt with a single text input
+func NewInput(id, title, body, placeholder, value string) *Model {
+	return New(id, title, body, []Field{{Placeholder: placeholder, Value: value}}, nil)
+}
+
+// A prompt with a vertical list of options
+func NewMenu(id, title string, options []string) *Model {
+	return New(id, title, "", nil, options)
+}
+
+func (m *Model) GetId() string {
+	return m.id
+}
+
+// Displays an error under the body, used by pages when validation of submitted values fails
+func (m *Model) SetError(err string) {
+	m.errMessage = err
+}
+
+func (m *Model) SetBody(body string) {
+	m.body = body
+}
+
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CancelledMsg{Id: m.id} }
+
+		case "enter":
+			return m, func() tea.Msg { return SubmittedMsg{Id: m.id, Values: m.values(), Option: m.optionIndex} }
+
+		case "tab", "down":
+			if len(m.fields) > 1 {
+				return m, m.setFocus(m.focusIndex + 1)
+			}
+			if m.optionIndex < len(m.options)-1 {
+				m.optionIndex++
+			}
+			return m, nil
+
+		case "shift+tab", "up":
+			if len(m.fields) > 1 {
+				return m, m.setFocus(m.focusIndex - 1)
+			}
+			if m.optionIndex > 0 {
+				m.optionIndex--
+			}
+			return m, nil
+
+		case "left", "right":
+			if m.isChoice(m.focusIndex) {
+				m.cycleChoice(msg.String() == "right")
+				return m, nil
+			}
+			if len(m.fields) == 0 {
+				if msg.String() == "right" && m.optionIndex < len(m.options)-1 {
+					m.optionIndex++
+				} else if msg.String() == "left" && m.optionIndex > 0 {
+					m.optionIndex--
+				}
+				return m, nil
+			}
+		}
+	}
+
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := range m.inputs {
+		if !m.isChoice(i) {
+			m.inputs[i], cmds[i] = m.inputs[i].Update(msg)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(m.title))
+
+	if m.body != "" {
+		b.WriteString("\n")
+		b.WriteString(bodyStyle.Render(m.body))
+	}
+
+	if len(m.fields) > 0 {
+		b.WriteString("\n")
+		for i, f := range m.fields {
+			b.WriteString("\n  ")
+			if f.Label != "" {
+				b.WriteString(labelStyle.Render(f.Label))
+			}
+			if m.isChoice(i) {
+				style := noStyle
+				if i == m.focusIndex {
+					style = focusedStyle
+				}
+				b.WriteString(style.Render(fmt.Sprintf("◂ %s ▸", f.Options[m.choices[i]])))
+			} else {
+				b.WriteString(m.inputs[i].View())
+			}
+		}
+	}
+
+	if m.errMessage != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("❌ %s", m.errMessage)))
+	}
+
+	if len(m.options) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.renderOptions())
+	}
+
+	b.WriteString("\n")
+	b.WriteString(hintStyle.Render(m.renderHint()))
+
+	return dialog.DialogBoxStyle.Render(b.String())
+}
+
+// Renders the prompt centered in the terminal the same way the loading dialog is rendered
+func (m *Model) ViewPlaced() string {
+	return dialog.PlaceDialog(m.View())
+}
+
+func (m *Model) renderOptions() string {
+	s := make([]string, len(m.options))
+	for i, o := range m.options {
+		if i == m.optionIndex {
+			s[i] = activeOptionStyle.Render(o)
+		} else {
+			s[i] = optionStyle.Render(o)
+		}
+	}
+
+	// Short lists like confirmations are rendered as buttons, anything longer is a menu
+	if len(m.options) <= 2 && len(m.fields) == 0 {
+		return lipgloss.NewStyle().Width(60).MarginTop(1).Align(lipgloss.Center).
+			Render(lipgloss.JoinHorizontal(lipgloss.Center, s...))
+	}
+
+	return lipgloss.NewStyle().Width(60).MarginTop(1).Padding(0, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, s...))
+}
+
+func (m *Model) renderHint() string {
+	if len(m.fields) > 1 {
+		return "tab next field • enter submit • esc cancel"
+	}
+
+	return "enter select • esc cancel"
+}
+
+func (m *Model) values() []string {
+	v := make([]string, len(m.fields))
+	for i := range m.fields {
+		if m.isChoice(i) {
+			v[i] = m.fields[i].Options[m.choices[i]]
+		} else {
+			v[i] = m.inputs[i].Value()
+		}
+	}
+
+	return v
+}
+
+func (m *Model) isChoice(i int) bool {
+	return i >= 0 && i < len(m.fields) && len(m.fields[i].Options) > 0
+}
+
+func (m *Model) cycleChoice(forward bool) {
+	n := len(m.fields[m.focusIndex].Options)
+	if forward {
+		m.choices[m.focusIndex] = (m.choices[m.focusIndex] + 1) % n
+	} else {
+		m.choices[m.focusIndex] = (m.choices[m.focusIndex] - 1 + n) % n
+	}
+}
+
+func (m *Model) setFocus(i int) tea.Cmd {
+	if len(m.fields) == 0 {
+		return nil
+	}
+
+	if i >= len(m.fields) {
+		i = 0
+	} else if i < 0 {
+		i = len(m.fields) - 1
+	}
+	m.focusIndex = i
+
+	var cmd tea.Cmd
+	for j := range m.inputs {
+		if j == i && !m.isChoice(j) {
+			cmd = m.inputs[j].Focus()
+			m.inputs[j].PromptStyle = focusedStyle
+			m.inputs[j].TextStyle = focusedStyle
+			continue
+		}
+		m.inputs[j].Blur()
+		m.inputs[j].PromptStyle = noStyle
+		m.inputs[j].TextStyle = noStyle
+	}
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+package qr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/term"
+)
+
+const (
+	// Past this version the modules get too small to be read reliably from a terminal by a phone
+	maxScannableVersion = 10
+
+	darkColor  = 16  // black in the 256 color palette
+	lightColor = 231 // white in the 256 color palette
+)
+
+// Renders content as a QR code using half block characters so every character holds two modules.
+// The colors are set explicitly so the code is dark on light whatever the terminal theme is.  The
+// returned warning is empty when the code should scan without problems.
+func Render(content string) (string, string, error) {
+	q, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		return "", "", err
+	}
+
+	bitmap := q.Bitmap()
+	var b strings.Builder
+
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			top := bitmap[y][x]
+			bottom := false
+			if y+1 < len(bitmap) {
+				bottom = bitmap[y+1][x]
+			}
+			fmt.Fprintf(&b, "\033[38;5;%d;48;5;%dm▀", getColor(top), getColor(bottom))
+		}
+		b.WriteString("\033[0m")
+		if y+2 < len(bitmap) {
+			b.WriteString("\n")
+		}
+	}
+
+	warnings := make([]string, 0)
+	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+	if width > 0 && (len(bitmap)+4 > width || len(bitmap)/2+8 > height) {
+		warnings = append(warnings, "The terminal is too small to show the whole code, enlarge it or shorten the url.")
+	}
+	if q.VersionNumber > maxScannableVersion {
+		warnings = append(warnings, fmt.Sprintf("The url is %v characters long, the code may be too dense to scan.", len(content)))
+	}
+
+	return b.String(), strings.Join(warnings, " "), nil
+}
+
+func getColor(dark bool) int {
+	if dark {
+		return darkColor
+	}
+
+	return lightColor
+}
@@ -9,6 +9,12 @@ var (
 				Foreground(lipgloss.Color("#ffffff")).
 				Background(lipgloss.Color("#9a87a1"))
 
+	selectedRowStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F25D93"))
+
+	highlightedSelectedRowStyle = highlightedRowStyle.Copy().
+					Foreground(lipgloss.Color("#F25D93"))
+
 	headerRowStyle = lipgloss.NewStyle().
 			Border(lipgloss.NormalBorder(), false, false, true).
 			BorderForeground(lipgloss.Color("#383838"))
@@ -41,6 +47,11 @@ var (
 			Background(lipgloss.Color("#6124DF")).
 			Padding(0, 1)
 
+	footerSelectionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#2E9E7A")).
+				Padding(0, 1)
+
 	footerFilterStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#FFFFFF")).
 				Background(lipgloss.Color("#FCA17D")).
@@ -36,13 +36,47 @@ func New(c []Column, hasFiltering bool) *Model {
 func (m *Model) SetData(r []Row) {
 	m.data = r
 	m.highlightedRowIndex = 0
+	m.firstVisibleRow = 0
+	m.selectedRows = make(map[int]bool)
 	m.isLoading = false
 }
 
+// Allows rows to be marked with the space key.  canSelect decides which rows are allowed to be
+// selected, pass nil to allow every row.
+func (m *Model) EnableSelection(canSelect func(r Row) bool) {
+	m.hasSelection = true
+	m.canSelect = canSelect
+	m.selectedRows = make(map[int]bool)
+}
+
+// Adds rows to the end without moving the cursor, used when more rows are streamed in
+func (m *Model) AppendData(r []Row) {
+	m.data = append(m.data, r...)
+	m.isLoading = false
+}
+
+// Lets tables wider than the terminal scroll through their columns with left and right
+func (m *Model) EnableHorizontalScroll() {
+	m.hasHorizontalScroll = true
+}
+
+// Changes rows in place, the cursor and the selection stay where they are
+func (m *Model) UpdateRows(update func(r Row)) {
+	for _, r := range m.data {
+		update(r)
+	}
+}
+
 func (m *Model) SetHasNextPage(hasNextPage bool) {
 	m.hasNextPage = hasNextPage
 }
 
+// Moves back to the first page, used when the data is reloaded from the start
+func (m *Model) ResetPaging() {
+	m.currentPageIndex = 0
+	m.hasNextPage = false
+}
+
 func (m *Model) SetFooterInfo(f string) {
 	m.footerInfo = f
 }
@@ -55,6 +89,41 @@ func (m *Model) GetHighlightedRow() *Row {
 	return nil
 }
 
+func (m *Model) GetHighlightedRowIndex() int {
+	return m.highlightedRowIndex
+}
+
+// Moves the cursor to the row at index i and scrolls it into view
+func (m *Model) SetHighlightedRow(i int) {
+	if i < 0 || i >= len(m.data) {
+		return
+	}
+
+	m.highlightedRowIndex = i
+	visible := m.getVisibleRowCount()
+	if i < m.firstVisibleRow {
+		m.firstVisibleRow = i
+	} else if visible > 0 && i >= m.firstVisibleRow+visible {
+		m.firstVisibleRow = i - visible + 1
+	}
+}
+
+// Returns the selected rows in the order they appear in the table
+func (m *Model) GetSelectedRows() []Row {
+	r := make([]Row, 0)
+	for i := range m.data {
+		if m.selectedRows[i] {
+			r = append(r, m.data[i])
+		}
+	}
+
+	return r
+}
+
+func (m *Model) ClearSelection() {
+	m.selectedRows = make(map[int]bool)
+}
+
 func (m *Model) getVisibleRowCount() int {
 	_, height, _ := term.GetSize(int(os.Stdout.Fd()))
 	calc := height - 6
@@ -75,6 +144,12 @@ func (m *Model) GetCurrentFilter() string {
 	return m.currentFilter
 }
 
+// Applies a filter as if it had been typed in, the owner of the table filters the rows itself
+func (m *Model) SetFilter(f string) {
+	m.currentFilter = f
+	m.filterInput.SetValue(f)
+}
+
 func (m *Model) Init() tea.Cmd {
 	cmds := make([]tea.Cmd, 0)
 	cmds = append(cmds, m.spinner.Tick)
@@ -99,10 +174,18 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 			m.handleDownKey()
 
 		case "right":
-			m.handleRightKey(&cmds)
+			if m.hasHorizontalScroll {
+				m.handleScrollRight()
+			} else {
+				m.handleRightKey(&cmds)
+			}
 
 		case "left":
-			m.handleLeftKey(&cmds)
+			if m.hasHorizontalScroll {
+				m.handleScrollLeft()
+			} else {
+				m.handleLeftKey(&cmds)
+			}
 
 		case "esc":
 			m.handleEscapeKey(&cmds)
@@ -115,6 +198,9 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 
 		case "enter":
 			m.handleEnterKey(&cmds)
+
+		case " ":
+			m.handleSpaceKey()
 		}
 	}
 
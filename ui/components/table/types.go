@@ -3,11 +3,21 @@ package table
 import (
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type Column struct {
 	Width int
 	Name  string
+
+	// By default only the last part of a path is shown, set to render the value untouched
+	ShowFullPath bool
+
+	// Shows the value as it is, long values are cut at the end like any other column
+	PlainText bool
+
+	// Picks a text color by value, nil keeps the default.  Highlighted and selected rows keep their colors.
+	Color func(value string) lipgloss.TerminalColor
 }
 
 type Row []string
@@ -32,6 +42,15 @@ type Model struct {
 	// Paging
 	hasNextPage      bool
 	currentPageIndex int
+
+	// Selection
+	hasSelection bool
+	canSelect    func(r Row) bool
+	selectedRows map[int]bool
+
+	// Horizontal scrolling, left and right move through the columns instead of the pages
+	hasHorizontalScroll bool
+	firstVisibleColumn  int
 }
 
 type FilterAppliedMsg struct {
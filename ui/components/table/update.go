@@ -95,3 +95,35 @@ func (m *Model) handleEnterKey(cmds *[]tea.Cmd) {
 		m.isFilterVisible = false
 	}
 }
+
+func (m *Model) handleSpaceKey() {
+	if !m.hasSelection || m.isFilterVisible || len(m.data) == 0 {
+		return
+	}
+
+	r := m.data[m.highlightedRowIndex]
+	if m.canSelect != nil && !m.canSelect(r) {
+		return
+	}
+
+	if m.selectedRows[m.highlightedRowIndex] {
+		delete(m.selectedRows, m.highlightedRowIndex)
+	} else {
+		m.selectedRows[m.highlightedRowIndex] = true
+	}
+
+	m.handleDownKey()
+}
+
+func (m *Model) handleScrollRight() {
+	first, last := m.getVisibleColumnRange()
+	if last < len(m.columns) && first < len(m.columns)-1 {
+		m.firstVisibleColumn++
+	}
+}
+
+func (m *Model) handleScrollLeft() {
+	if m.firstVisibleColumn > 0 {
+		m.firstVisibleColumn--
+	}
+}
@@ -17,10 +17,34 @@ func (m *Model) renderFilter() string {
 	return ""
 }
 
+// The columns that fit the terminal, every column unless horizontal scrolling is enabled
+func (m *Model) getVisibleColumnRange() (int, int) {
+	if !m.hasHorizontalScroll {
+		return 0, len(m.columns)
+	}
+
+	width, _, _ := term.GetSize(int(os.Stdout.Fd()))
+	// Leave room for the border
+	room := width - 4
+	last := m.firstVisibleColumn
+	for last < len(m.columns) && (room >= m.columns[last].Width || last == m.firstVisibleColumn) {
+		room -= m.columns[last].Width
+		last++
+	}
+
+	return m.firstVisibleColumn, last
+}
+
+func (m *Model) getVisibleColumns() []Column {
+	first, last := m.getVisibleColumnRange()
+
+	return m.columns[first:last]
+}
+
 func (m *Model) renderHeader() string {
 	s := make([]string, len(m.columns))
 
-	for _, c := range m.columns {
+	for _, c := range m.getVisibleColumns() {
 		style := headerRowStyle.Copy().
 			Width(c.Width)
 		s = append(s, style.Render(strings.ToUpper(c.Name)))
@@ -40,10 +64,11 @@ func (m *Model) renderRows() string {
 
 	index := 0
 	for i := m.firstVisibleRow; i < lastRow+m.firstVisibleRow; i++ {
-		row := make([]string, len(m.columns))
+		first, last := m.getVisibleColumnRange()
+		row := make([]string, last-first)
 
-		for j, c := range m.columns {
-			row[j] = m.renderColumn(m.data[i][j], c, i, j)
+		for j := first; j < last; j++ {
+			row[j-first] = m.renderColumn(m.data[i][j], m.columns[j], i, j-first, last-first)
 		}
 
 		s[index] = lipgloss.JoinHorizontal(lipgloss.Center, row...)
@@ -56,14 +81,23 @@ func (m *Model) renderRows() string {
 	return h.Render(lipgloss.JoinVertical(lipgloss.Center, s...))
 }
 
-func (m *Model) renderColumn(data string, c Column, currentRow, currentCol int) string {
+func (m *Model) renderColumn(data string, c Column, currentRow, currentCol, columnCount int) string {
 	style := lipgloss.NewStyle().Width(c.Width)
-	if currentRow == m.highlightedRowIndex {
+	if currentRow == m.highlightedRowIndex && m.selectedRows[currentRow] {
+		style = highlightedSelectedRowStyle.Copy().Width(c.Width)
+	} else if currentRow == m.highlightedRowIndex {
 		style = highlightedRowStyle.Copy().Width(c.Width)
+	} else if m.selectedRows[currentRow] {
+		style = selectedRowStyle.Copy().Width(c.Width)
+	}
+	if c.Color != nil && currentRow != m.highlightedRowIndex && !m.selectedRows[currentRow] {
+		if color := c.Color(data); color != nil {
+			style = style.Copy().Foreground(color)
+		}
 	}
 	if currentCol == 0 {
 		style = style.Copy().Padding(0, 0, 0, 1)
-	} else if currentCol == len(m.columns)-1 {
+	} else if currentCol == columnCount-1 {
 		style = style.Copy().Padding(0, 1, 0, 0)
 	}
 
@@ -71,7 +105,7 @@ func (m *Model) renderColumn(data string, c Column, currentRow, currentCol int)
 
 	// if data has folder path, strip off all folders but the last
 	folders := strings.Split(dataFinal, "/")
-	if len(folders) > 1 {
+	if len(folders) > 1 && !c.ShowFullPath && !c.PlainText {
 		// path ended with / so last item is empty
 		if folders[len(folders)-1] == "" {
 			dataFinal = folders[len(folders)-2]
@@ -83,7 +117,12 @@ func (m *Model) renderColumn(data string, c Column, currentRow, currentCol int)
 	// If data is too large for the column, to prevent wrapping, truncate and add ellipses
 	calc := c.Width - 5
 	if len(dataFinal) > calc && calc > 0 {
-		dataFinal = fmt.Sprintf("%s...", dataFinal[:calc])
+		if c.ShowFullPath {
+			// the end of a long path is the interesting part
+			dataFinal = fmt.Sprintf("...%s", dataFinal[len(dataFinal)-calc:])
+		} else {
+			dataFinal = fmt.Sprintf("%s...", dataFinal[:calc])
+		}
 	}
 
 	return style.Render(dataFinal)
@@ -91,7 +130,7 @@ func (m *Model) renderColumn(data string, c Column, currentRow, currentCol int)
 
 func (m *Model) renderFooter() string {
 	width := 0
-	for _, w := range m.columns {
+	for _, w := range m.getVisibleColumns() {
 		width += w.Width
 	}
 
@@ -106,11 +145,19 @@ func (m *Model) renderFooter() string {
 		right.WriteString(footerLoadingTextStyle.Render("loading"))
 	}
 
+	if len(m.selectedRows) > 0 {
+		right.WriteString(footerSelectionStyle.Render(fmt.Sprintf("\uf00c %v", len(m.selectedRows))))
+	}
+
 	if m.currentFilter != "" {
 		right.WriteString(footerFilterStyle.Render(fmt.Sprintf("\uf002 %s", m.currentFilter)))
 	}
 
-	right.WriteString(m.renderPagingFooter())
+	if m.hasHorizontalScroll {
+		right.WriteString(m.renderColumnFooter())
+	} else {
+		right.WriteString(m.renderPagingFooter())
+	}
 
 	right.WriteString(footerNavStyle.Render(m.renderNavFooter()))
 
@@ -165,3 +212,21 @@ func (m *Model) renderPagingFooter() string {
 
 	return footerPagingStyle.Render(strings.Join(chars, " "))
 }
+
+func (m *Model) renderColumnFooter() string {
+	first, last := m.getVisibleColumnRange()
+	chars := make([]string, 0)
+
+	chars = append(chars, "\uf0db")
+	chars = append(chars, fmt.Sprintf("%v-%v/%v", first+1, last, len(m.columns)))
+
+	if first > 0 {
+		chars = append(chars, "\uf04a") // left arrow
+	}
+
+	if last < len(m.columns) {
+		chars = append(chars, "\uf04e") // right arrow
+	}
+
+	return footerPagingStyle.Render(strings.Join(chars, " "))
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Long running operations such as deleting a whole prefix run in their own goroutine and report back
+// through a channel.  Every ProgressMsg carries the command needed to keep listening, so the page
+// handling it must return msg.Next() until a DoneMsg arrives.
+type ProgressMsg struct {
+	Id      string
+	Done    int64
+	Total   int64
+	Message string
+	ch      chan tea.Msg
+}
+
+type DoneMsg struct {
+	Id     string
+	Result interface{}
+	Err    error
+}
+
+// Used by the running operation to publish how far along it is
+type Reporter func(done, total int64, message string)
+
+func Run(id string, fn func(report Reporter) (interface{}, error)) tea.Cmd {
+	ch := make(chan tea.Msg)
+
+	go func() {
+		report := func(done, total int64, message string) {
+			ch <- ProgressMsg{Id: id, Done: done, Total: total, Message: message, ch: ch}
+		}
+
+		r, err := fn(report)
+		ch <- DoneMsg{Id: id, Result: r, Err: err}
+		close(ch)
+	}()
+
+	return listen(ch)
+}
+
+func (m ProgressMsg) Next() tea.Cmd {
+	return listen(m.ch)
+}
+
+func (m ProgressMsg) Percent() float64 {
+	if m.Total <= 0 {
+		return 0
+	}
+
+	return float64(m.Done) / float64(m.Total)
+}
+
+func listen(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
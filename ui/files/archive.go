@@ -0,0 +1,268 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const downloadMemberPrompt = "download-member"
+
+// A zip or tar object browsed like a folder
+type archiveModel struct {
+	bucket  string
+	key     string
+	size    int64
+	entries []api.ArchiveEntry
+	path    string // Folder inside of the archive, empty or ending with a slash
+}
+
+// The archive browsed last, kept so coming back from the preview of one of its files does not read it again
+var lastArchive *archiveModel
+
+type archiveListedMsg struct {
+	archive *archiveModel
+	err     error
+}
+
+type memberDownloadedMsg struct {
+	path string
+	err  error
+}
+
+func getObjectSize(key string) (int64, bool) {
+	for _, f := range model.files {
+		if *f.Key == key {
+			return *f.Size, true
+		}
+	}
+
+	return 0, false
+}
+
+func handleOpenArchive(m *types.UiModel, key string, cmds *[]tea.Cmd) {
+	size, ok := getObjectSize(key)
+	if !ok {
+		return
+	}
+
+	a := &archiveModel{
+		bucket: m.GetCurrentBucket(),
+		key:    key,
+		size:   size,
+	}
+	showLoading(fmt.Sprintf("Reading %s", key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		entries, err := api.ListArchive(m.Session, a.bucket, a.key, a.size)
+		a.entries = entries
+		return archiveListedMsg{a, err}
+	})
+}
+
+func handleArchiveListedMsg(m *types.UiModel, msg archiveListedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Could not read the archive", msg.err.Error()), cmds)
+		return
+	}
+
+	model.archive = msg.archive
+	lastArchive = msg.archive
+	model.table.ResetPaging()
+	showArchiveRows(m, "")
+}
+
+// Goes back into the archive a previewed file came from, false when it is not the archive read last
+func reopenArchive(m *types.UiModel) bool {
+	a := lastArchive
+	if a == nil || a.bucket != m.GetCurrentBucket() || a.key != m.GetCurrentObject() {
+		return false
+	}
+
+	model.archive = a
+	model.isLoading = false
+	a.path = path.Dir(m.GetCurrentMember()) + "/"
+	if a.path == "./" {
+		a.path = ""
+	}
+	model.focusKey = m.GetCurrentMember()
+	showArchiveRows(m, "")
+
+	return true
+}
+
+// Shows the folders and files directly inside of the current folder of the archive, like a listing with
+// a delimiter would
+func showArchiveRows(m *types.UiModel, filter string) {
+	a := model.archive
+	directories := make([]string, 0)
+	seen := make(map[string]bool)
+	files := make([]api.ArchiveEntry, 0)
+
+	for _, e := range a.entries {
+		if e.Name == a.path || !strings.HasPrefix(e.Name, a.path+filter) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(e.Name, a.path)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			// Folders are often only there as part of the names of their files
+			d := a.path + rest[:i+1]
+			if !seen[d] {
+				seen[d] = true
+				directories = append(directories, d)
+			}
+			continue
+		}
+		files = append(files, e)
+	}
+
+	sort.Strings(directories)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	r := make([]table.Row, 0, len(directories)+len(files))
+	for _, d := range directories {
+		r = append(r, table.Row{icons.GetDirectoryIcon(), d, "", "", "", ""})
+	}
+	for _, f := range files {
+		r = append(r, table.Row{
+			icons.GetIcon(f.Name),
+			f.Name,
+			utils.GetFriendlyByteDisplay(f.Size),
+			"",
+			f.Modified.Format(time.DateTime),
+			"",
+		})
+	}
+	model.table.SetHasNextPage(false)
+	model.table.SetData(r)
+
+	if model.focusKey != "" {
+		for i, row := range r {
+			if row[1] == model.focusKey {
+				model.table.SetHighlightedRow(i)
+			}
+		}
+		model.focusKey = ""
+	}
+
+	model.table.SetFooterInfo(fmt.Sprintf("%s/%s › %s", m.GetCurrentBucket(), a.key, a.path))
+}
+
+func handleArchiveKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Let the table clear the filter first
+		if model.table.GetCurrentFilter() != "" {
+			break
+		}
+		handleArchiveEscKeyMsg(m, cmds)
+
+	case "enter":
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			break
+		}
+		if isDirectoryRow(*r) {
+			model.archive.path = (*r)[1]
+			model.table.ResetPaging()
+			showArchiveRows(m, "")
+			break
+		}
+		*cmds = append(*cmds, m.SetCurrentMember(model.archive.key, (*r)[1]))
+
+	case "p":
+		r := model.table.GetHighlightedRow()
+		if r != nil && !isDirectoryRow(*r) {
+			*cmds = append(*cmds, m.SetCurrentMember(model.archive.key, (*r)[1]))
+		}
+
+	case "D":
+		handleDownloadMemberKeyMsg(m, cmds)
+	}
+}
+
+// Goes up a folder inside of the archive, or leaves it from its root
+func handleArchiveEscKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	a := model.archive
+	if a.path == "" {
+		model.archive = nil
+		model.focusKey = a.key
+		model.isLoading = true
+		refreshFiles(m, cmds)
+		return
+	}
+
+	model.focusKey = a.path
+	a.path = path.Dir(strings.TrimSuffix(a.path, "/")) + "/"
+	if a.path == "./" {
+		a.path = ""
+	}
+	model.table.ResetPaging()
+	showArchiveRows(m, "")
+}
+
+func handleDownloadMemberKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil || isDirectoryRow(*r) {
+		return
+	}
+
+	model.downloadMember = (*r)[1]
+	body := fmt.Sprintf("Save %s from %s to", model.downloadMember, model.archive.key)
+	openPrompt(prompt.NewInput(downloadMemberPrompt, "Download", body, "local path", filepath.Base(model.downloadMember)), cmds)
+}
+
+func handleDownloadMemberConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	p := strings.TrimSpace(msg.Values[0])
+	if p == "" {
+		model.prompt.SetError("enter a path")
+		return
+	}
+
+	closePrompt()
+	a := model.archive
+	member := model.downloadMember
+	model.downloadMember = ""
+	showLoading(fmt.Sprintf("Downloading %s", member), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		f, err := os.Create(p)
+		if err != nil {
+			return memberDownloadedMsg{p, err}
+		}
+
+		err = api.WriteArchiveMember(m.Session, a.bucket, a.key, a.size, member, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(p)
+		}
+
+		return memberDownloadedMsg{p, err}
+	})
+}
+
+func handleMemberDownloadedMsg(m *types.UiModel, msg memberDownloadedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Download failed", msg.err.Error()), cmds)
+		return
+	}
+
+	openPrompt(prompt.NewMessage("", "Downloaded", fmt.Sprintf("Saved to %s", msg.path)), cmds)
+}
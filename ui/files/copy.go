@@ -0,0 +1,245 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	copyPrompt = "copy"
+	copyTask   = "copy"
+)
+
+// What the copy / move prompt is going to act on.  Either a list of selected keys or a whole prefix.
+type pendingCopy struct {
+	move   bool
+	keys   []string
+	prefix string
+}
+
+type copyResult struct {
+	move         bool
+	copied       int
+	bytes        int64
+	destination  string
+	deleteFailed []api.DeleteError
+	deleteErr    error // The removal of the sources stopped after every copy succeeded
+}
+
+func handleCopyKeyMsg(m *types.UiModel, move bool, cmds *[]tea.Cmd) {
+	pc := &pendingCopy{move: move, keys: getSelectedKeys()}
+	if len(pc.keys) == 0 {
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+
+		if isDirectoryRow(*r) {
+			pc.prefix = (*r)[1]
+		} else {
+			pc.keys = []string{(*r)[1]}
+		}
+	}
+	model.pendingCopy = pc
+
+	title := "Copy"
+	if move {
+		title = "Move"
+	}
+
+	body := fmt.Sprintf("%s %v selected objects to:", title, len(pc.keys))
+	if pc.prefix != "" {
+		body = fmt.Sprintf("%s everything under %s to:", title, getS3Uri(m, pc.prefix))
+	} else if len(pc.keys) == 1 {
+		body = fmt.Sprintf("%s %s to:", title, getS3Uri(m, pc.keys[0]))
+	}
+
+	fields := []prompt.Field{
+		{Label: "Destination", Placeholder: "s3://bucket/prefix/", Value: getS3Uri(m, m.GetCurrentPath())},
+		{Label: "Metadata", Options: []string{"Preserve", "Replace"}},
+		{Label: "Content-Type", Placeholder: "unchanged"},
+		{Label: "New metadata", Placeholder: "key=value, ..."},
+		{Label: "Tags", Options: []string{"Preserve", "Replace"}},
+		{Label: "New tags", Placeholder: "key=value, ..."},
+	}
+	openPrompt(prompt.New(copyPrompt, title, body, fields, nil), cmds)
+}
+
+func handleCopyConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pc := model.pendingCopy
+	if pc == nil {
+		closePrompt()
+		return
+	}
+
+	destBucket, destPrefix, err := api.ParseS3Uri(strings.TrimSpace(msg.Values[0]))
+	if err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+	if pc.prefix != "" && destBucket == m.GetCurrentBucket() && strings.HasPrefix(destPrefix+"/", pc.prefix) {
+		model.prompt.SetError(fmt.Sprintf("the destination is inside %s", pc.prefix))
+		return
+	}
+
+	opts := api.CopyOptions{
+		ReplaceMetadata: msg.Values[1] == "Replace",
+		ContentType:     strings.TrimSpace(msg.Values[2]),
+		ReplaceTags:     msg.Values[4] == "Replace",
+	}
+	if opts.Metadata, err = utils.ParseKeyValues(msg.Values[3]); err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+	if opts.Tags, err = utils.ParseKeyValues(msg.Values[5]); err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+
+	closePrompt()
+	model.pendingCopy = nil
+	bucket := m.GetCurrentBucket()
+	path := m.GetCurrentPath()
+	sizes := getFileSizes()
+
+	*cmds = append(*cmds, task.Run(copyTask, func(report task.Reporter) (interface{}, error) {
+		result := copyResult{move: pc.move, destination: fmt.Sprintf("s3://%s/%s", destBucket, destPrefix)}
+
+		items := make([]api.CopyItem, 0)
+		if pc.prefix != "" {
+			report(0, 0, fmt.Sprintf("Listing %s", pc.prefix))
+			objects, err := api.GetAllObjects(m.Session, bucket, pc.prefix)
+			if err != nil {
+				return result, err
+			}
+			for _, o := range objects {
+				items = append(items, api.CopyItem{
+					SourceBucket: bucket,
+					SourceKey:    *o.Key,
+					Size:         *o.Size,
+					DestBucket:   destBucket,
+					DestKey:      getDestinationKey(*o.Key, path, destPrefix, false),
+				})
+			}
+		} else {
+			for _, k := range pc.keys {
+				items = append(items, api.CopyItem{
+					SourceBucket: bucket,
+					SourceKey:    k,
+					Size:         sizes[k],
+					DestBucket:   destBucket,
+					DestKey:      getDestinationKey(k, path, destPrefix, len(pc.keys) == 1),
+				})
+			}
+		}
+
+		var total int64
+		for _, i := range items {
+			if i.SourceBucket == i.DestBucket && i.SourceKey == i.DestKey {
+				return result, fmt.Errorf("%s would be copied onto itself", i.SourceKey)
+			}
+			total += i.Size
+		}
+		// A destination that is also a source would be overwritten before it is copied itself
+		for i, c := range findRenameCollisions(items, nil) {
+			if c {
+				return result, fmt.Errorf("s3://%s/%s is one of the sources and would be overwritten", items[i].DestBucket, items[i].DestKey)
+			}
+		}
+
+		copied, err := api.CopyObjects(m.Session, items, opts, func(c int64, i api.CopyItem) {
+			report(c, total, fmt.Sprintf(
+				"Copying %s (%s / %s)",
+				i.SourceKey,
+				utils.GetFriendlyByteDisplay(c),
+				utils.GetFriendlyByteDisplay(total)))
+		})
+		result.bytes = copied
+		if err != nil {
+			return result, err
+		}
+		result.copied = len(items)
+
+		// Move is a copy followed by a delete of the sources once every copy succeeded
+		if pc.move {
+			keys := make([]string, len(items))
+			for i, item := range items {
+				keys[i] = item.SourceKey
+			}
+
+			n := int64(len(keys))
+			report(0, n, fmt.Sprintf("Removing %v source objects", n))
+			result.deleteFailed, result.deleteErr = api.DeleteObjects(m.Session, bucket, keys, func(done int) {
+				report(int64(done), n, fmt.Sprintf("Removed %v / %v source objects", done, n))
+			})
+		}
+
+		return result, nil
+	}))
+}
+
+func handleCopyDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(copyResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Copy failed", msg.Err.Error()), cmds)
+		return
+	}
+
+	// The objects are only moved once the sources are gone
+	verb, title := "Copied", "Copy finished"
+	if r.move {
+		title = "Move finished"
+		if r.deleteErr == nil {
+			verb = "Moved"
+		}
+		if r.deleteErr != nil || len(r.deleteFailed) > 0 {
+			title = "Move finished with errors"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %v objects (%s) to %s.", verb, r.copied, utils.GetFriendlyByteDisplay(r.bytes), r.destination)
+	if r.deleteErr != nil {
+		fmt.Fprintf(&b, "\n\nRemoving the sources failed, some of them may be removed already:\n%s", r.deleteErr)
+	}
+	if len(r.deleteFailed) > 0 {
+		fmt.Fprintf(&b, "\n\n%v sources could not be removed:\n", len(r.deleteFailed))
+		utils.WriteFailures(&b, r.deleteFailed)
+	}
+
+	openPrompt(prompt.NewMessage("", title, b.String()), cmds)
+}
+
+// Keys keep their path relative to the folder currently being browsed.  When a single object is
+// copied to a destination that does not end with a slash the destination is used as the new key.
+func getDestinationKey(key, currentPath, destPrefix string, single bool) string {
+	if single && destPrefix != "" && !strings.HasSuffix(destPrefix, "/") {
+		return destPrefix
+	}
+
+	if destPrefix != "" && !strings.HasSuffix(destPrefix, "/") {
+		destPrefix += "/"
+	}
+
+	return destPrefix + strings.TrimPrefix(key, currentPath)
+}
+
+func getFileSizes() map[string]int64 {
+	sizes := make(map[string]int64, len(model.files))
+	for _, f := range model.files {
+		sizes[*f.Key] = *f.Size
+	}
+
+	return sizes
+}
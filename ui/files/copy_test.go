@@ -0,0 +1,49 @@
+package files
+
+import (
+	"reflect"
+	"s3-viewer/api"
+	"testing"
+)
+
+func TestGetDestinationKey(t *testing.T) {
+	tests := []struct {
+		key, currentPath, destPrefix string
+		single                       bool
+		want                         string
+	}{
+		{"a/b.txt", "a/", "c/", false, "c/b.txt"},
+		{"a/b.txt", "a/", "c", false, "c/b.txt"},
+		{"a/d/b.txt", "a/", "c/", false, "c/d/b.txt"},
+		{"a/b.txt", "a/", "", false, "b.txt"},
+		{"a/b.txt", "", "c/", false, "c/a/b.txt"},
+		{"a/b.txt", "a/", "c/new.txt", true, "c/new.txt"},
+		{"a/b.txt", "a/", "c/", true, "c/b.txt"},
+		{"a/b.txt", "a/", "", true, "b.txt"},
+	}
+
+	for _, tt := range tests {
+		got := getDestinationKey(tt.key, tt.currentPath, tt.destPrefix, tt.single)
+		if got != tt.want {
+			t.Errorf("getDestinationKey(%q, %q, %q, %v) = %q, want %q", tt.key, tt.currentPath, tt.destPrefix, tt.single, got, tt.want)
+		}
+	}
+}
+
+func TestCopyCollisions(t *testing.T) {
+	// Moving p/ to p/ from the root copies p/a to p/p/a before p/p/a itself is copied
+	items := []api.CopyItem{
+		{SourceBucket: "b", SourceKey: "p/a", DestBucket: "b", DestKey: "p/p/a"},
+		{SourceBucket: "b", SourceKey: "p/p/a", DestBucket: "b", DestKey: "p/p/p/a"},
+	}
+	if got := findRenameCollisions(items, nil); !reflect.DeepEqual(got, []bool{true, false}) {
+		t.Errorf("got %v", got)
+	}
+
+	// The same keys in another bucket are fine
+	items[0].DestBucket = "other"
+	items[1].DestBucket = "other"
+	if got := findRenameCollisions(items, nil); !reflect.DeepEqual(got, []bool{false, false}) {
+		t.Errorf("got %v across buckets", got)
+	}
+}
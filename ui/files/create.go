@@ -0,0 +1,112 @@
+package files
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	newFolderPrompt = "new-folder"
+	newFilePrompt   = "new-file"
+)
+
+type newFileEditedMsg struct {
+	key  string
+	path string
+	err  error
+}
+
+type objectCreatedMsg struct {
+	key string
+	err error
+}
+
+func handleNewFolderKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	body := fmt.Sprintf("Create a folder in %s", getS3Uri(m, m.GetCurrentPath()))
+	openPrompt(prompt.NewInput(newFolderPrompt, "New folder", body, "folder name", ""), cmds)
+}
+
+func handleNewFileKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	body := fmt.Sprintf("Create a file in %s, it is opened in your editor before being uploaded.", getS3Uri(m, m.GetCurrentPath()))
+	openPrompt(prompt.NewInput(newFilePrompt, "New file", body, "file name", ""), cmds)
+}
+
+func handleNewFolderConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	name := strings.Trim(strings.TrimSpace(msg.Values[0]), "/")
+	if name == "" {
+		model.prompt.SetError("enter a folder name")
+		return
+	}
+
+	closePrompt()
+	key := fmt.Sprintf("%s%s/", m.GetCurrentPath(), name)
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Creating %s", key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		return objectCreatedMsg{key, api.CreateFolder(m.Session, bucket, key)}
+	})
+}
+
+// The file is written to a temp file and the program is suspended while the editor is open
+func handleNewFileConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	name := strings.TrimLeft(strings.TrimSpace(msg.Values[0]), "/")
+	if name == "" || strings.HasSuffix(name, "/") {
+		model.prompt.SetError("enter a file name")
+		return
+	}
+
+	key := m.GetCurrentPath() + name
+	f, err := utils.CreateTempFileFor(key)
+	if err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+	f.Close()
+
+	closePrompt()
+	*cmds = append(*cmds, tea.ExecProcess(utils.GetEditorCmd(f.Name()), func(err error) tea.Msg {
+		return newFileEditedMsg{key, f.Name(), err}
+	}))
+}
+
+func handleNewFileEditedMsg(m *types.UiModel, msg newFileEditedMsg, cmds *[]tea.Cmd) {
+	if msg.err != nil {
+		os.Remove(msg.path)
+		openPrompt(prompt.NewMessage("", "New file failed", msg.err.Error()), cmds)
+		return
+	}
+
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Uploading %s", msg.key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		defer os.Remove(msg.path)
+
+		b, err := os.ReadFile(msg.path)
+		if err != nil {
+			return objectCreatedMsg{msg.key, err}
+		}
+
+		contentType := mime.TypeByExtension(filepath.Ext(msg.key))
+		return objectCreatedMsg{msg.key, api.PutObject(m.Session, bucket, msg.key, b, contentType)}
+	})
+}
+
+func handleObjectCreatedMsg(m *types.UiModel, msg objectCreatedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Create failed", msg.err.Error()), cmds)
+		return
+	}
+
+	model.focusKey = msg.key
+	refreshFiles(m, cmds)
+}
@@ -0,0 +1,174 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	deleteConfirmPrompt = "delete-confirm"
+	deletePrefixPrompt  = "delete-prefix"
+	deleteTask          = "delete"
+)
+
+// Keys waiting for the user to confirm the delete
+type pendingDelete struct {
+	keys   []string
+	prefix string
+}
+
+type deleteDryRunMsg struct {
+	prefix  string
+	objects []*s3.Object
+	err     error
+}
+
+type deleteResult struct {
+	total   int
+	deleted int
+	failed  []api.DeleteError
+}
+
+func handleDeleteKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	keys := getSelectedKeys()
+	if len(keys) == 0 {
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+
+		// A directory is deleted recursively, so count what is under it before asking for confirmation
+		if isDirectoryRow(*r) {
+			prefix := (*r)[1]
+			showLoading(fmt.Sprintf("Counting objects under %s", prefix), cmds)
+			*cmds = append(*cmds, func() tea.Msg {
+				o, err := api.GetAllObjects(m.Session, m.GetCurrentBucket(), prefix)
+				return deleteDryRunMsg{prefix, o, err}
+			})
+			return
+		}
+
+		keys = []string{(*r)[1]}
+	}
+
+	model.pendingDelete = &pendingDelete{keys: keys}
+
+	body := fmt.Sprintf("%s will be permanently deleted.", getS3Uri(m, keys[0]))
+	if len(keys) > 1 {
+		body = fmt.Sprintf("%v selected objects will be permanently deleted.", len(keys))
+	}
+	openPrompt(prompt.NewConfirm(deleteConfirmPrompt, "Delete", body, "Delete"), cmds)
+}
+
+func handleDeleteDryRunMsg(m *types.UiModel, msg deleteDryRunMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Delete failed", msg.err.Error()), cmds)
+		return
+	}
+
+	var size int64
+	keys := make([]string, len(msg.objects))
+	for i, o := range msg.objects {
+		keys[i] = *o.Key
+		size += *o.Size
+	}
+	model.pendingDelete = &pendingDelete{keys: keys, prefix: msg.prefix}
+
+	body := fmt.Sprintf(
+		"Dry run: %v objects (%s) under %s will be permanently deleted.\n\nType the prefix name to confirm:",
+		len(keys),
+		utils.GetFriendlyByteDisplay(size),
+		getS3Uri(m, msg.prefix))
+	openPrompt(prompt.NewInput(deletePrefixPrompt, "Delete prefix", body, msg.prefix, ""), cmds)
+}
+
+func handleDeleteConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pd := model.pendingDelete
+	if pd == nil {
+		closePrompt()
+		return
+	}
+
+	if msg.Id == deleteConfirmPrompt && msg.Option != 0 {
+		model.pendingDelete = nil
+		closePrompt()
+		return
+	}
+
+	if msg.Id == deletePrefixPrompt {
+		typed := strings.TrimSpace(msg.Values[0])
+		if typed != pd.prefix && typed != strings.TrimSuffix(pd.prefix, "/") {
+			model.prompt.SetError("the typed name does not match the prefix")
+			return
+		}
+	}
+
+	closePrompt()
+	model.pendingDelete = nil
+	bucket := m.GetCurrentBucket()
+
+	*cmds = append(*cmds, task.Run(deleteTask, func(report task.Reporter) (interface{}, error) {
+		total := int64(len(pd.keys))
+		report(0, total, fmt.Sprintf("Deleting %v objects", total))
+
+		if len(pd.keys) == 1 {
+			if err := api.DeleteObject(m.Session, bucket, pd.keys[0]); err != nil {
+				return deleteResult{total: 1}, err
+			}
+			return deleteResult{total: 1, deleted: 1}, nil
+		}
+
+		// A failed request stops the delete, only the batches before it count
+		processed := 0
+		failed, err := api.DeleteObjects(m.Session, bucket, pd.keys, func(done int) {
+			processed = done
+			report(int64(done), total, fmt.Sprintf("Deleted %v / %v objects", done, total))
+		})
+
+		return deleteResult{total: len(pd.keys), deleted: processed - len(failed), failed: failed}, err
+	}))
+}
+
+func handleDeleteDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(deleteResult)
+	if msg.Err != nil && r.total > 1 {
+		// Part of the objects may be gone already, say how much
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\n\nDeleted %v of %v objects before the error.", msg.Err, r.deleted, r.total)
+		if len(r.failed) > 0 {
+			fmt.Fprintf(&b, " %v could not be deleted:\n", len(r.failed))
+			utils.WriteFailures(&b, r.failed)
+		}
+		openPrompt(prompt.NewMessage("", "Delete failed", b.String()), cmds)
+		return
+	}
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Delete failed", msg.Err.Error()), cmds)
+		return
+	}
+
+	if len(r.failed) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Deleted %v objects, %v could not be deleted:\n", r.deleted, len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Delete finished with errors", b.String()), cmds)
+		return
+	}
+
+	if r.deleted > 1 {
+		openPrompt(prompt.NewMessage("", "Delete finished", fmt.Sprintf("Deleted %v objects.", r.deleted)), cmds)
+	}
+}
@@ -0,0 +1,278 @@
+package files
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	editConflictPrompt = "edit-conflict"
+
+	// Editing is meant for small config files, the whole object is held in memory
+	maxEditSize = 10 * 1024 * 1024
+)
+
+// Options of the prompt shown when the object changed while it was being edited
+const (
+	editAbortOption = iota
+	editDiffOption
+	editOverwriteOption
+)
+
+// An object being edited.  The edit is kept in path until it is uploaded or given up.
+type pendingEdit struct {
+	key    string
+	path   string
+	object *api.EditableObject
+	edited []byte
+}
+
+type editDownloadedMsg struct {
+	key    string
+	object *api.EditableObject
+	err    error
+}
+
+type editorClosedMsg struct {
+	err error
+}
+
+type editCheckedMsg struct {
+	etag string
+	err  error
+}
+
+type editDiffReadyMsg struct {
+	path string // The current version of the object
+	err  error
+}
+
+type editDiffClosedMsg struct {
+	path string
+}
+
+type editUploadedMsg struct {
+	key string
+	err error
+}
+
+func handleEditKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil || isDirectoryRow(*r) {
+		return
+	}
+
+	key := (*r)[1]
+	if size, ok := getObjectSize(key); ok && size > maxEditSize {
+		body := fmt.Sprintf("Only objects up to %s can be edited", utils.GetFriendlyByteDisplay(maxEditSize))
+		openPrompt(prompt.NewMessage("", "Too big to edit", body), cmds)
+		return
+	}
+
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Downloading %s", key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		o, err := api.GetEditableObject(m.Session, bucket, key)
+		return editDownloadedMsg{key, o, err}
+	})
+}
+
+// The object is written to a temp file and the program is suspended while the editor is open
+func handleEditDownloadedMsg(m *types.UiModel, msg editDownloadedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Edit failed", msg.err.Error()), cmds)
+		return
+	}
+
+	f, err := utils.CreateTempFileFor(msg.key)
+	if err == nil {
+		_, err = f.Write(msg.object.Body)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}
+	if err != nil {
+		openPrompt(prompt.NewMessage("", "Edit failed", err.Error()), cmds)
+		return
+	}
+
+	model.pendingEdit = &pendingEdit{
+		key:    msg.key,
+		path:   f.Name(),
+		object: msg.object,
+	}
+	*cmds = append(*cmds, tea.ExecProcess(utils.GetEditorCmd(f.Name()), func(err error) tea.Msg {
+		return editorClosedMsg{err}
+	}))
+}
+
+// Nothing is uploaded when the file was not changed, otherwise the object is checked for changes made
+// by someone else since it was downloaded
+func handleEditorClosedMsg(m *types.UiModel, msg editorClosedMsg, cmds *[]tea.Cmd) {
+	e := model.pendingEdit
+	if e == nil {
+		return
+	}
+
+	var b []byte
+	err := msg.err
+	if err == nil {
+		b, err = os.ReadFile(e.path)
+	}
+	if err != nil {
+		model.pendingEdit = nil
+		os.Remove(e.path)
+		openPrompt(prompt.NewMessage("", "Edit failed", err.Error()), cmds)
+		return
+	}
+
+	if bytes.Equal(b, e.object.Body) {
+		model.pendingEdit = nil
+		os.Remove(e.path)
+		openPrompt(prompt.NewMessage("", "No changes", fmt.Sprintf("%s was not changed, nothing was uploaded", e.key)), cmds)
+		return
+	}
+
+	e.edited = b
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Checking %s", e.key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		info, err := api.GetObjectInfo(m.Session, bucket, e.key)
+		if err != nil {
+			return editCheckedMsg{err: err}
+		}
+		return editCheckedMsg{info.ETag, nil}
+	})
+}
+
+func handleEditCheckedMsg(m *types.UiModel, msg editCheckedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	e := model.pendingEdit
+	if msg.err != nil {
+		model.pendingEdit = nil
+		body := fmt.Sprintf("Could not check %s: %s\n\nYour edit is kept in %s", e.key, msg.err, e.path)
+		openPrompt(prompt.NewMessage("", "Edit failed", body), cmds)
+		return
+	}
+
+	if msg.etag == e.object.ETag {
+		uploadEdit(m, cmds)
+		return
+	}
+	openEditConflictPrompt(cmds)
+}
+
+// Abort comes first so the edit is not lost by pressing enter
+func openEditConflictPrompt(cmds *[]tea.Cmd) {
+	body := fmt.Sprintf("%s was changed by someone else while you were editing it.", model.pendingEdit.key)
+	openPrompt(prompt.New(editConflictPrompt, "Object changed", body, nil, []string{"Abort", "Diff", "Overwrite"}), cmds)
+}
+
+func handleEditConflictConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	closePrompt()
+	switch msg.Option {
+	case editOverwriteOption:
+		uploadEdit(m, cmds)
+
+	case editDiffOption:
+		e := model.pendingEdit
+		bucket := m.GetCurrentBucket()
+		showLoading(fmt.Sprintf("Downloading the current %s", e.key), cmds)
+		*cmds = append(*cmds, func() tea.Msg {
+			return downloadCurrentVersion(m, bucket, e.key)
+		})
+
+	default:
+		abortEdit(cmds)
+	}
+}
+
+func downloadCurrentVersion(m *types.UiModel, bucket, key string) editDiffReadyMsg {
+	b, err := api.GetObject(m.Session, bucket, key)
+	if err != nil {
+		return editDiffReadyMsg{err: err}
+	}
+
+	f, err := utils.CreateTempFileFor(key)
+	if err != nil {
+		return editDiffReadyMsg{err: err}
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return editDiffReadyMsg{err: err}
+	}
+
+	return editDiffReadyMsg{f.Name(), nil}
+}
+
+// Shows what the upload would change in the object as it is now, in the pager
+func handleEditDiffReadyMsg(m *types.UiModel, msg editDiffReadyMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openEditConflictPrompt(cmds)
+		model.prompt.SetError(msg.err.Error())
+		return
+	}
+
+	e := model.pendingEdit
+	c := exec.Command(
+		"sh", "-c", `diff -u -L "$1" -L "$2" "$3" "$4" | ${PAGER:-less}`, "sh",
+		fmt.Sprintf("%s (current)", getS3Uri(m, e.key)), fmt.Sprintf("%s (yours)", getS3Uri(m, e.key)),
+		msg.path, e.path)
+	*cmds = append(*cmds, tea.ExecProcess(c, func(error) tea.Msg {
+		return editDiffClosedMsg{msg.path}
+	}))
+}
+
+func handleEditDiffClosedMsg(m *types.UiModel, msg editDiffClosedMsg, cmds *[]tea.Cmd) {
+	os.Remove(msg.path)
+	openEditConflictPrompt(cmds)
+}
+
+// The edited file is left behind so the changes are not lost
+func abortEdit(cmds *[]tea.Cmd) {
+	e := model.pendingEdit
+	model.pendingEdit = nil
+	body := fmt.Sprintf("%s was not uploaded, your edit is kept in %s", e.key, e.path)
+	openPrompt(prompt.NewMessage("", "Edit aborted", body), cmds)
+}
+
+func uploadEdit(m *types.UiModel, cmds *[]tea.Cmd) {
+	e := model.pendingEdit
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Uploading %s", e.key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		return editUploadedMsg{e.key, api.PutEditedObject(m.Session, bucket, e.key, e.edited, e.object)}
+	})
+}
+
+func handleEditUploadedMsg(m *types.UiModel, msg editUploadedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	e := model.pendingEdit
+	model.pendingEdit = nil
+	if msg.err != nil {
+		body := fmt.Sprintf("%s\n\nYour edit is kept in %s", msg.err, e.path)
+		openPrompt(prompt.NewMessage("", "Upload failed", body), cmds)
+		return
+	}
+
+	os.Remove(e.path)
+	model.focusKey = msg.key
+	refreshFiles(m, cmds)
+}
@@ -7,12 +7,16 @@ import (
 	"s3-viewer/ui/components/dialog"
 	"s3-viewer/ui/components/help"
 	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/components/prompt"
 	spin "s3-viewer/ui/components/spinner"
 	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
 	"s3-viewer/ui/types"
 	"s3-viewer/ui/utils"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"golang.org/x/term"
 
@@ -32,6 +36,28 @@ type filesModel struct {
 	isLoading          bool
 	table              *table.Model
 	continuationTokens []*string // Used for current, next, previous page
+	loadingMessage     string    // Shown instead of the table while a background lookup runs
+	prompt             *prompt.Model
+	progress           *task.ProgressMsg
+	pendingDelete      *pendingDelete
+	pendingCopy        *pendingCopy
+	pendingRename      *pendingRename
+	renamePreview      *renamePreview
+	focusKey           string // Row to highlight once the next listing arrives
+	shareKey           string
+	shareUrl           string
+	qrCode             string // Rendered QR code dialog, shown until a key is pressed
+	locations          []api.Location
+	archive            *archiveModel // Set while browsing inside of a zip or tar object
+	downloadMember     string
+	pendingEdit        *pendingEdit
+	pendingMetadata    *pendingMetadata
+	metadataPreview    *metadataPreview
+	tagsPanel          *tagsPanel
+	pendingTags        *pendingTags
+	tagsPreview        *tagsPreview
+	pendingStorage     *pendingStorage
+	restoreStatus      map[string]*api.RestoreStatus // Archived objects of the page that were restored
 }
 
 type getFilesMsg struct {
@@ -44,8 +70,9 @@ func initTable() *table.Model {
 		{Name: "", Width: 3}, // Icon column
 		{Name: "Key", Width: 50},
 		{Name: "Size", Width: 15},
-		{Name: "Last Modified", Width: 35},
-		{Name: "Owner", Width: 35},
+		{Name: "Storage Class", Width: 22, Color: storageClassColor},
+		{Name: "Last Modified", Width: 25},
+		{Name: "Owner", Width: 23},
 	}
 
 	return table.New(columns, true)
@@ -61,11 +88,56 @@ func getFileRow(f *s3.Object) table.Row {
 		icons.GetIcon(*f.Key),
 		*f.Key,
 		utils.GetFriendlyByteDisplay(*f.Size),
+		getStorageClassCell(aws.StringValue(f.StorageClass), model.restoreStatus[*f.Key]),
 		f.LastModified.Format(time.DateTime),
 		owner,
 	}
 }
 
+func isDirectoryKey(key string) bool {
+	return strings.HasSuffix(key, "/")
+}
+
+func isDirectoryRow(r table.Row) bool {
+	return isDirectoryKey(r[1])
+}
+
+// Keys of the selected file rows.  Directories can not be selected so these are always objects.
+func getSelectedKeys() []string {
+	rows := model.table.GetSelectedRows()
+	keys := make([]string, len(rows))
+	for i, r := range rows {
+		keys[i] = r[1]
+	}
+
+	return keys
+}
+
+func getS3Uri(m *types.UiModel, key string) string {
+	return fmt.Sprintf("s3://%s/%s", m.GetCurrentBucket(), key)
+}
+
+func openPrompt(p *prompt.Model, cmds *[]tea.Cmd) {
+	model.prompt = p
+	*cmds = append(*cmds, p.Init())
+}
+
+func closePrompt() {
+	model.prompt = nil
+}
+
+func showLoading(msg string, cmds *[]tea.Cmd) {
+	model.loadingMessage = msg
+	*cmds = append(*cmds, model.spinner.Tick)
+}
+
+// Reloads the first page of the current path, used after an action changed the contents of the bucket
+func refreshFiles(m *types.UiModel, cmds *[]tea.Cmd) {
+	model.continuationTokens = make([]*string, 0)
+	model.table.ResetPaging()
+	*cmds = append(*cmds, createGetFilesMsg(m, m.GetCurrentPath(), model.table.GetCurrentFilter(), nil))
+}
+
 func createGetFilesMsg(m *types.UiModel, path, filter string, continuationToken *string) func() tea.Msg {
 	return func() tea.Msg {
 		o, err := api.GetObjects(m.Session, m.GetCurrentBucket(), path, filter, continuationToken)
@@ -86,20 +158,20 @@ func Init(m *types.UiModel) tea.Cmd {
 		table:              initTable(),
 		continuationTokens: make([]*string, 0),
 	}
+	model.table.EnableSelection(func(r table.Row) bool {
+		return model.archive == nil && !isDirectoryRow(r)
+	})
 
 	cmds := make([]tea.Cmd, 0)
 	cmds = append(cmds, model.spinner.Tick)
 	cmds = append(cmds, model.table.Init())
 
-	cmds = append(cmds, func() tea.Msg {
-		o, err := api.GetObjects(m.Session, m.GetCurrentBucket(), "/", "", nil)
-		model.isLoading = false
-		if err != nil {
-			return getFilesMsg{nil, err}
-		}
-
-		return getFilesMsg{o, nil}
-	})
+	// Coming back from a preview lands on the folder and row the object was opened from
+	if m.GetCurrentMember() != "" && reopenArchive(m) {
+		return tea.Batch(cmds...)
+	}
+	model.focusKey = m.GetCurrentObject()
+	cmds = append(cmds, createGetFilesMsg(m, m.GetCurrentPath(), "", nil))
 
 	return tea.Batch(cmds...)
 }
@@ -109,7 +181,10 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case getFilesMsg:
-		handleGetFilesMsg(m, msg)
+		handleGetFilesMsg(m, msg, &cmds)
+
+	case restoreStatusMsg:
+		handleRestoreStatusMsg(m, msg)
 
 	case table.FilterAppliedMsg:
 		handleFilterAppliedMsg(m, msg, &cmds)
@@ -120,7 +195,111 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 	case table.PrevPageMsg:
 		handlePrevPageMsg(m, msg, &cmds)
 
+	case deleteDryRunMsg:
+		handleDeleteDryRunMsg(m, msg, &cmds)
+
+	case renameCheckedMsg:
+		handleRenameCheckedMsg(m, msg, &cmds)
+
+	case renamePlanMsg:
+		handleRenamePlanMsg(m, msg, &cmds)
+
+	case newFileEditedMsg:
+		handleNewFileEditedMsg(m, msg, &cmds)
+
+	case objectCreatedMsg:
+		handleObjectCreatedMsg(m, msg, &cmds)
+
+	case shareMsg:
+		handleShareMsg(m, msg, &cmds)
+
+	case archiveListedMsg:
+		handleArchiveListedMsg(m, msg, &cmds)
+
+	case memberDownloadedMsg:
+		handleMemberDownloadedMsg(m, msg, &cmds)
+
+	case logsListedMsg:
+		handleLogsListedMsg(m, msg, &cmds)
+
+	case editDownloadedMsg:
+		handleEditDownloadedMsg(m, msg, &cmds)
+
+	case editorClosedMsg:
+		handleEditorClosedMsg(m, msg, &cmds)
+
+	case editCheckedMsg:
+		handleEditCheckedMsg(m, msg, &cmds)
+
+	case editDiffReadyMsg:
+		handleEditDiffReadyMsg(m, msg, &cmds)
+
+	case editDiffClosedMsg:
+		handleEditDiffClosedMsg(m, msg, &cmds)
+
+	case editUploadedMsg:
+		handleEditUploadedMsg(m, msg, &cmds)
+
+	case metadataLoadedMsg:
+		handleMetadataLoadedMsg(m, msg, &cmds)
+
+	case tagsLoadedMsg:
+		handleTagsLoadedMsg(m, msg, &cmds)
+
+	case tagsSavedMsg:
+		handleTagsSavedMsg(m, msg, &cmds)
+
+	case prompt.SubmittedMsg:
+		handlePromptSubmittedMsg(m, msg, &cmds)
+
+	case prompt.CancelledMsg:
+		handlePromptCancelledMsg(m, msg, &cmds)
+
+	case task.ProgressMsg:
+		model.progress = &msg
+		cmds = append(cmds, msg.Next())
+
+	case task.DoneMsg:
+		handleTaskDoneMsg(m, msg, &cmds)
+
 	case tea.KeyMsg:
+		// A prompt is open so it receives all of the keys
+		if model.prompt != nil {
+			var cmd tea.Cmd
+			model.prompt, cmd = model.prompt.Update(msg)
+			return cmd
+		}
+
+		// Ignore keys while a background task or lookup is running
+		if model.progress != nil || model.loadingMessage != "" {
+			return nil
+		}
+
+		if model.qrCode != "" {
+			model.qrCode = ""
+			return nil
+		}
+
+		if model.renamePreview != nil {
+			handleRenamePreviewKeyMsg(m, msg, &cmds)
+			return tea.Batch(cmds...)
+		}
+
+		if model.metadataPreview != nil {
+			handleMetadataPreviewKeyMsg(m, msg, &cmds)
+			return tea.Batch(cmds...)
+		}
+
+		if model.tagsPreview != nil {
+			handleTagsPreviewKeyMsg(m, msg, &cmds)
+			return tea.Batch(cmds...)
+		}
+
+		if model.tagsPanel != nil {
+			handleTagsPanelKeyMsg(m, msg, &cmds)
+			return tea.Batch(cmds...)
+		}
+
 		// Filter is visible so allow the table to handle this command and hide the filter
 		if model.table.IsFilterVisible() {
 			var cmd tea.Cmd
@@ -129,6 +308,11 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 			break
 		}
 
+		if model.archive != nil {
+			handleArchiveKeyMsg(m, msg, &cmds)
+			break
+		}
+
 		switch msg.String() {
 		case "esc":
 			// If a filter is currently applied, do nothing and let table clear the filter
@@ -140,10 +324,65 @@ func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
 
 		case "enter":
 			handleEnterKeyMsg(m, msg, &cmds)
+
+		case "p":
+			handlePreviewKeyMsg(m, &cmds)
+
+		case "d":
+			handleDeleteKeyMsg(m, &cmds)
+
+		case "c":
+			handleCopyKeyMsg(m, false, &cmds)
+
+		case "m":
+			handleCopyKeyMsg(m, true, &cmds)
+
+		case "r":
+			handleRenameKeyMsg(m, &cmds)
+
+		case "R":
+			handleBulkRenameKeyMsg(m, &cmds)
+
+		case "n":
+			handleNewFolderKeyMsg(m, &cmds)
+
+		case "N":
+			handleNewFileKeyMsg(m, &cmds)
+
+		case "s":
+			handleShareKeyMsg(m, &cmds)
+
+		case "y":
+			handleCopyLocationKeyMsg(m, &cmds)
+
+		case "L":
+			handleAnalyzeLogsKeyMsg(m, &cmds)
+
+		case "e":
+			handleEditKeyMsg(m, &cmds)
+
+		case "M":
+			handleMetadataKeyMsg(m, &cmds)
+
+		case "t":
+			handleTagsKeyMsg(m, &cmds)
+
+		case "S":
+			handleStorageClassKeyMsg(m, &cmds)
+
+		case "G":
+			handleRestoreKeyMsg(m, &cmds)
 		}
 	}
 
-	if model.isLoading {
+	// Forward the rest of the messages (cursor blink) to an open prompt
+	if _, ok := msg.(tea.KeyMsg); !ok && model.prompt != nil {
+		var pc tea.Cmd
+		model.prompt, pc = model.prompt.Update(msg)
+		cmds = append(cmds, pc)
+	}
+
+	if model.isLoading || model.loadingMessage != "" {
 		var sc tea.Cmd
 		model.spinner, sc = model.spinner.Update(msg)
 		cmds = append(cmds, sc)
@@ -171,31 +410,74 @@ func View(m *types.UiModel) string {
 		return dialog.GetLoadingDialog(fmt.Sprintf("Loading Bucket %s", m.GetCurrentBucket()), model.spinner)
 	}
 
-	if model.directories != nil || model.files != nil {
-		// Get terminal size and place dialog in the center
-		docStyle := lipgloss.NewStyle()
-		width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+	if model.prompt != nil {
+		return model.prompt.ViewPlaced()
+	}
 
-		if width > 0 {
-			docStyle = docStyle.MaxWidth(width)
-		}
-		if height > 0 {
-			docStyle = docStyle.MaxHeight(height)
-		}
+	if model.qrCode != "" {
+		return dialog.PlaceDialog(model.qrCode)
+	}
+
+	if model.progress != nil {
+		return dialog.GetProgressDialog(model.progress.Message, model.progress.Percent())
+	}
+
+	if model.loadingMessage != "" {
+		return dialog.GetLoadingDialog(model.loadingMessage, model.spinner)
+	}
+
+	if model.renamePreview != nil {
+		return placeWithHelp(model.renamePreview.table.View(), help.GetRenamePreviewHelp())
+	}
+
+	if model.metadataPreview != nil {
+		return placeWithHelp(model.metadataPreview.table.View(), help.GetChangePreviewHelp())
+	}
+
+	if model.tagsPreview != nil {
+		return placeWithHelp(model.tagsPreview.table.View(), help.GetChangePreviewHelp())
+	}
 
-		final := lipgloss.JoinVertical(
-			lipgloss.Center,
+	if model.tagsPanel != nil {
+		return placeWithHelp(model.tagsPanel.table.View(), help.GetTagsHelp())
+	}
+
+	if model.archive != nil {
+		return placeWithHelp(
+			model.table.View(),
+			help.GetArchiveHelp(model.table.IsFilterVisible(), model.table.GetCurrentFilter()))
+	}
+
+	if model.directories != nil || model.files != nil {
+		return placeWithHelp(
 			model.table.View(),
 			help.GetFilesHelp(model.table.IsFilterVisible(), model.table.GetCurrentFilter()))
+	}
 
-		p := lipgloss.Place(
-			width, height,
-			lipgloss.Center, lipgloss.Center,
-			final,
-		)
+	return "YOU ARE NOW IN THE Buckets VIEW"
+}
 
-		return docStyle.Render(p)
+// Get terminal size and place the table and its help in the center
+func placeWithHelp(t, h string) string {
+	docStyle := lipgloss.NewStyle()
+	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+
+	if width > 0 {
+		docStyle = docStyle.MaxWidth(width)
+	}
+	if height > 0 {
+		docStyle = docStyle.MaxHeight(height)
 	}
 
-	return "YOU ARE NOW IN THE Buckets VIEW"
+	// The help grows with every action so wrap it to the width of the table
+	h = lipgloss.NewStyle().Width(lipgloss.Width(t)).Align(lipgloss.Center).Render(h)
+	final := lipgloss.JoinVertical(lipgloss.Center, t, h)
+
+	p := lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		final,
+	)
+
+	return docStyle.Render(p)
 }
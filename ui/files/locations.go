@@ -0,0 +1,27 @@
+package files
+
+import (
+	"s3-viewer/api"
+	"s3-viewer/ui/components/location"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func handleCopyLocationKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil {
+		return
+	}
+
+	model.locations = api.GetLocations(m.Session, m.GetCurrentBucket(), (*r)[1])
+	openPrompt(location.NewPrompt(getS3Uri(m, (*r)[1]), model.locations), cmds)
+}
+
+func handleCopyLocationConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	closePrompt()
+	l := model.locations[msg.Option]
+	model.locations = nil
+	openPrompt(location.Copy(l), cmds)
+}
@@ -0,0 +1,66 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A folder of logs, like a day of CloudTrail, is read whole so it should not be too big
+const maxLogObjects = 2000
+
+type logsListedMsg struct {
+	prefix string
+	keys   []string
+	err    error
+}
+
+// Opens the selected objects, the highlighted one or everything in the highlighted folder in the log
+// viewer so they are analyzed together
+func handleAnalyzeLogsKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	keys := getSelectedKeys()
+	if len(keys) > 0 {
+		*cmds = append(*cmds, m.SetCurrentObjects(keys))
+		return
+	}
+
+	r := model.table.GetHighlightedRow()
+	if r == nil {
+		return
+	}
+	if !isDirectoryRow(*r) {
+		*cmds = append(*cmds, m.SetCurrentObjects([]string{(*r)[1]}))
+		return
+	}
+
+	prefix := (*r)[1]
+	showLoading(fmt.Sprintf("Listing %s", prefix), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		objects, err := api.GetAllObjects(m.Session, m.GetCurrentBucket(), prefix)
+		keys := make([]string, 0, len(objects))
+		for _, o := range objects {
+			if !isDirectoryKey(*o.Key) {
+				keys = append(keys, *o.Key)
+			}
+		}
+		return logsListedMsg{prefix, keys, err}
+	})
+}
+
+func handleLogsListedMsg(m *types.UiModel, msg logsListedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	switch {
+	case msg.err != nil:
+		openPrompt(prompt.NewMessage("", "Could not list the logs", msg.err.Error()), cmds)
+	case len(msg.keys) == 0:
+		openPrompt(prompt.NewMessage("", "No logs", fmt.Sprintf("%s has no objects", msg.prefix)), cmds)
+	case len(msg.keys) > maxLogObjects:
+		body := fmt.Sprintf("%s has %v objects, pick a folder with at most %v", msg.prefix, len(msg.keys), maxLogObjects)
+		openPrompt(prompt.NewMessage("", "Too many logs", body), cmds)
+	default:
+		*cmds = append(*cmds, m.SetCurrentObjects(msg.keys))
+	}
+}
@@ -0,0 +1,374 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	metadataPrompt     = "metadata"
+	bulkMetadataPrompt = "bulk-metadata"
+	metadataScanTask   = "metadata-scan"
+	metadataTask       = "metadata"
+)
+
+// Objects the metadata form is going to change.  Either one object, a list of selected keys or a prefix.
+type pendingMetadata struct {
+	keys   []string
+	prefix string
+	object *api.ObjectMetadata // The object edited on its own, with its current metadata
+}
+
+// What the form asks for.  A header set to an empty value is removed.
+type metadataChange struct {
+	headers         map[string]string
+	setMetadata     map[string]string
+	removeMetadata  []string
+	replaceMetadata bool // setMetadata is the whole user metadata, used when editing a single object
+}
+
+// An object with the metadata it is going to get
+type metadataUpdate struct {
+	object   *api.ObjectMetadata
+	headers  map[string]string
+	metadata map[string]string
+	changes  []string
+}
+
+// Shown as a table before the metadata of several objects is changed
+type metadataPreview struct {
+	table   *table.Model
+	updates []metadataUpdate
+}
+
+type metadataLoadedMsg struct {
+	object *api.ObjectMetadata
+	err    error
+}
+
+type metadataScanResult struct {
+	updates   []metadataUpdate
+	unchanged int
+}
+
+type metadataResult struct {
+	changed int
+	failed  []string
+}
+
+func handleMetadataKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	pm := &pendingMetadata{keys: getSelectedKeys()}
+	if len(pm.keys) == 0 {
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+
+		if !isDirectoryRow(*r) {
+			// A single object gets a form filled with its current metadata
+			key := (*r)[1]
+			bucket := m.GetCurrentBucket()
+			showLoading(fmt.Sprintf("Reading the metadata of %s", key), cmds)
+			*cmds = append(*cmds, func() tea.Msg {
+				o, err := api.GetObjectMetadata(m.Session, bucket, key)
+				return metadataLoadedMsg{o, err}
+			})
+			return
+		}
+		pm.prefix = (*r)[1]
+	}
+	model.pendingMetadata = pm
+
+	body := fmt.Sprintf("Change the metadata of the %v selected objects.", len(pm.keys))
+	if pm.prefix != "" {
+		body = fmt.Sprintf("Change the metadata of every object under %s.", getS3Uri(m, pm.prefix))
+	}
+	body += " Empty fields are left unchanged, - removes a header."
+
+	fields := make([]prompt.Field, 0)
+	for _, h := range api.MetadataHeaders {
+		fields = append(fields, prompt.Field{Label: h, Placeholder: "unchanged"})
+	}
+	fields = append(fields, prompt.Field{Label: "Set metadata", Placeholder: "key=value, ..."})
+	fields = append(fields, prompt.Field{Label: "Remove metadata", Placeholder: "key, ..."})
+	openPrompt(prompt.New(bulkMetadataPrompt, "Metadata", body, fields, nil), cmds)
+}
+
+func handleMetadataLoadedMsg(m *types.UiModel, msg metadataLoadedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Metadata", msg.err.Error()), cmds)
+		return
+	}
+	model.pendingMetadata = &pendingMetadata{keys: []string{msg.object.Key}, object: msg.object}
+
+	fields := make([]prompt.Field, 0)
+	for _, h := range api.MetadataHeaders {
+		fields = append(fields, prompt.Field{Label: h, Placeholder: "not set", Value: msg.object.Headers[h]})
+	}
+	fields = append(fields, prompt.Field{Label: "Metadata", Placeholder: "key=value, ...", Value: utils.FormatKeyValues(msg.object.Metadata)})
+
+	body := fmt.Sprintf("Metadata of %s. The object is copied onto itself to change it.", getS3Uri(m, msg.object.Key))
+	openPrompt(prompt.New(metadataPrompt, "Metadata", body, fields, nil), cmds)
+}
+
+func handleMetadataConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pm := model.pendingMetadata
+	if pm == nil {
+		closePrompt()
+		return
+	}
+
+	change, err := parseMetadataChange(msg)
+	if err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+
+	closePrompt()
+	model.pendingMetadata = nil
+	bucket := m.GetCurrentBucket()
+
+	if pm.object != nil {
+		u := change.apply(pm.object)
+		if len(u.changes) == 0 {
+			openPrompt(prompt.NewMessage("", "Metadata", "Nothing was changed."), cmds)
+			return
+		}
+		*cmds = append(*cmds, runMetadataUpdates(m, []metadataUpdate{u}))
+		return
+	}
+
+	// Every object is read first so the preview shows exactly what changes
+	*cmds = append(*cmds, task.Run(metadataScanTask, func(report task.Reporter) (interface{}, error) {
+		result := metadataScanResult{}
+
+		keys := pm.keys
+		if pm.prefix != "" {
+			report(0, 0, fmt.Sprintf("Listing %s", pm.prefix))
+			objects, err := api.GetAllObjects(m.Session, bucket, pm.prefix)
+			if err != nil {
+				return result, err
+			}
+			keys = make([]string, 0, len(objects))
+			for _, o := range objects {
+				if !isDirectoryKey(*o.Key) {
+					keys = append(keys, *o.Key)
+				}
+			}
+		}
+
+		n := int64(len(keys))
+		for i, k := range keys {
+			report(int64(i), n, fmt.Sprintf("Reading the metadata of %s", k))
+			o, err := api.GetObjectMetadata(m.Session, bucket, k)
+			if err != nil {
+				return result, fmt.Errorf("reading %s: %w", k, err)
+			}
+
+			u := change.apply(o)
+			if len(u.changes) == 0 {
+				result.unchanged++
+				continue
+			}
+			result.updates = append(result.updates, u)
+		}
+
+		return result, nil
+	}))
+}
+
+func parseMetadataChange(msg prompt.SubmittedMsg) (metadataChange, error) {
+	c := metadataChange{headers: make(map[string]string)}
+	n := len(api.MetadataHeaders)
+
+	for i, h := range api.MetadataHeaders {
+		v := strings.TrimSpace(msg.Values[i])
+		switch {
+		case msg.Id == metadataPrompt:
+			// The form of a single object holds every value, an empty one removes the header
+			c.headers[h] = v
+		case v == "-":
+			c.headers[h] = ""
+		case v != "":
+			c.headers[h] = v
+		}
+	}
+
+	var err error
+	if c.setMetadata, err = utils.ParseKeyValues(msg.Values[n]); err != nil {
+		return c, err
+	}
+	if msg.Id == metadataPrompt {
+		c.replaceMetadata = true
+		return c, nil
+	}
+
+	for _, k := range strings.Split(msg.Values[n+1], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			c.removeMetadata = append(c.removeMetadata, k)
+		}
+	}
+	if len(c.headers) == 0 && len(c.setMetadata) == 0 && len(c.removeMetadata) == 0 {
+		return c, fmt.Errorf("fill in at least one field")
+	}
+
+	return c, nil
+}
+
+// Works out the new metadata of o and describes what differs.  S3 stores user metadata keys in lower case.
+func (c metadataChange) apply(o *api.ObjectMetadata) metadataUpdate {
+	u := metadataUpdate{
+		object:   o,
+		headers:  make(map[string]string),
+		metadata: make(map[string]string),
+	}
+
+	for _, h := range api.MetadataHeaders {
+		u.headers[h] = o.Headers[h]
+		if v, ok := c.headers[h]; ok {
+			u.headers[h] = v
+		}
+		if u.headers[h] != o.Headers[h] {
+			u.changes = append(u.changes, fmt.Sprintf("%s: %s → %s", h, orDash(o.Headers[h]), orDash(u.headers[h])))
+		}
+	}
+
+	if !c.replaceMetadata {
+		for k, v := range o.Metadata {
+			u.metadata[k] = v
+		}
+	}
+	for k, v := range c.setMetadata {
+		u.metadata[strings.ToLower(k)] = v
+	}
+	for _, k := range c.removeMetadata {
+		delete(u.metadata, strings.ToLower(k))
+	}
+
+	keys := make([]string, 0)
+	for k := range o.Metadata {
+		keys = append(keys, k)
+	}
+	for k := range u.metadata {
+		if _, ok := o.Metadata[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		old, hadOld := o.Metadata[k]
+		v, hasNew := u.metadata[k]
+		if hadOld != hasNew || old != v {
+			u.changes = append(u.changes, fmt.Sprintf("%s: %s → %s", k, orDash(old), orDash(v)))
+		}
+	}
+
+	return u
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func handleMetadataScanDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	r, _ := msg.Result.(metadataScanResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Metadata failed", msg.Err.Error()), cmds)
+		return
+	}
+	if len(r.updates) == 0 {
+		openPrompt(prompt.NewMessage("", "Metadata", fmt.Sprintf("None of the %v objects would change.", r.unchanged)), cmds)
+		return
+	}
+
+	preview := &metadataPreview{
+		updates: r.updates,
+		table: table.New([]table.Column{
+			{Name: "Key", Width: 50, ShowFullPath: true},
+			{Name: "Changes", Width: 90, PlainText: true},
+		}, false),
+	}
+
+	rows := make([]table.Row, len(r.updates))
+	for i, u := range r.updates {
+		rows[i] = table.Row{u.object.Key, strings.Join(u.changes, "; ")}
+	}
+	preview.table.SetData(rows)
+	preview.table.SetFooterInfo(fmt.Sprintf("%v objects change, %v unchanged", len(r.updates), r.unchanged))
+	model.metadataPreview = preview
+}
+
+func handleMetadataPreviewKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		model.metadataPreview = nil
+
+	case "enter":
+		updates := model.metadataPreview.updates
+		model.metadataPreview = nil
+		*cmds = append(*cmds, runMetadataUpdates(m, updates))
+
+	default:
+		var cmd tea.Cmd
+		model.metadataPreview.table, cmd = model.metadataPreview.table.Update(msg)
+		*cmds = append(*cmds, cmd)
+	}
+}
+
+// Objects that fail are listed at the end instead of stopping the rest
+func runMetadataUpdates(m *types.UiModel, updates []metadataUpdate) tea.Cmd {
+	bucket := m.GetCurrentBucket()
+
+	return task.Run(metadataTask, func(report task.Reporter) (interface{}, error) {
+		result := metadataResult{}
+		n := int64(len(updates))
+		for i, u := range updates {
+			report(int64(i), n, fmt.Sprintf("Updating %s", u.object.Key))
+			err := api.ReplaceObjectMetadata(m.Session, bucket, u.object, u.headers, u.metadata)
+			if err != nil {
+				result.failed = append(result.failed, fmt.Sprintf("%s: %s", u.object.Key, err))
+				continue
+			}
+			result.changed++
+		}
+
+		return result, nil
+	})
+}
+
+func handleMetadataDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(metadataResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Metadata failed", msg.Err.Error()), cmds)
+		return
+	}
+
+	if len(r.failed) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Updated %v objects, %v could not be updated:\n", r.changed, len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Metadata finished with errors", b.String()), cmds)
+		return
+	}
+
+	openPrompt(prompt.NewMessage("", "Metadata updated", fmt.Sprintf("Updated %v objects.", r.changed)), cmds)
+}
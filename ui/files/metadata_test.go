@@ -0,0 +1,136 @@
+package files
+
+import (
+	"reflect"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"testing"
+)
+
+func TestParseMetadataChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		values []string
+		want   metadataChange
+		isErr  bool
+	}{
+		{
+			"bulk",
+			bulkMetadataPrompt,
+			[]string{"text/plain", "", "-", " ", "", "a=1", "b, c"},
+			metadataChange{
+				headers:        map[string]string{"Content-Type": "text/plain", "Content-Disposition": ""},
+				setMetadata:    map[string]string{"a": "1"},
+				removeMetadata: []string{"b", "c"},
+			},
+			false,
+		},
+		{"bulk without changes", bulkMetadataPrompt, []string{"", "", "", "", "", "", " , "}, metadataChange{}, true},
+		{"bulk invalid metadata", bulkMetadataPrompt, []string{"", "", "", "", "", "a", ""}, metadataChange{}, true},
+		{
+			"single",
+			metadataPrompt,
+			[]string{"text/plain", "", "", "gzip", "", "a=1"},
+			metadataChange{
+				headers: map[string]string{
+					"Content-Type": "text/plain", "Cache-Control": "", "Content-Disposition": "",
+					"Content-Encoding": "gzip", "Content-Language": "",
+				},
+				setMetadata:     map[string]string{"a": "1"},
+				replaceMetadata: true,
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMetadataChange(prompt.SubmittedMsg{Id: tt.id, Values: tt.values})
+			if (err != nil) != tt.isErr {
+				t.Fatalf("got error %v", err)
+			}
+			if !tt.isErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataChangeApply(t *testing.T) {
+	object := &api.ObjectMetadata{
+		Key:      "a.txt",
+		Headers:  map[string]string{"Content-Type": "text/plain", "Cache-Control": "no-cache"},
+		Metadata: map[string]string{"owner": "alice", "team": "data"},
+	}
+
+	tests := []struct {
+		name     string
+		change   metadataChange
+		headers  map[string]string
+		metadata map[string]string
+		changes  []string
+	}{
+		{
+			"nothing",
+			metadataChange{},
+			map[string]string{"Content-Type": "text/plain", "Cache-Control": "no-cache"},
+			map[string]string{"owner": "alice", "team": "data"},
+			nil,
+		},
+		{
+			"same values",
+			metadataChange{headers: map[string]string{"Content-Type": "text/plain"}, setMetadata: map[string]string{"owner": "alice"}},
+			map[string]string{"Content-Type": "text/plain", "Cache-Control": "no-cache"},
+			map[string]string{"owner": "alice", "team": "data"},
+			nil,
+		},
+		{
+			"headers",
+			metadataChange{headers: map[string]string{"Content-Type": "application/json", "Cache-Control": "", "Content-Language": "en"}},
+			map[string]string{"Content-Type": "application/json", "Cache-Control": "", "Content-Language": "en"},
+			map[string]string{"owner": "alice", "team": "data"},
+			[]string{"Content-Type: text/plain → application/json", "Cache-Control: no-cache → -", "Content-Language: - → en"},
+		},
+		{
+			"set and remove",
+			metadataChange{setMetadata: map[string]string{"Owner": "bob", "env": "prod"}, removeMetadata: []string{"TEAM", "missing"}},
+			map[string]string{"Content-Type": "text/plain", "Cache-Control": "no-cache"},
+			map[string]string{"owner": "bob", "env": "prod"},
+			[]string{"env: - → prod", "owner: alice → bob", "team: data → -"},
+		},
+		{
+			"replace",
+			metadataChange{setMetadata: map[string]string{"owner": "alice"}, replaceMetadata: true},
+			map[string]string{"Content-Type": "text/plain", "Cache-Control": "no-cache"},
+			map[string]string{"owner": "alice"},
+			[]string{"team: data → -"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.change.apply(object)
+			if u.object != object {
+				t.Error("the update is not for the object")
+			}
+			// Headers that are not set are compared as missing
+			for h, v := range u.headers {
+				if v == "" {
+					if _, ok := tt.headers[h]; !ok {
+						delete(u.headers, h)
+					}
+				}
+			}
+			if !reflect.DeepEqual(u.headers, tt.headers) {
+				t.Errorf("headers = %v, want %v", u.headers, tt.headers)
+			}
+			if !reflect.DeepEqual(u.metadata, tt.metadata) {
+				t.Errorf("metadata = %v, want %v", u.metadata, tt.metadata)
+			}
+			if !reflect.DeepEqual(u.changes, tt.changes) {
+				t.Errorf("changes = %q, want %q", u.changes, tt.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package files
+
+import (
+	"fmt"
+	"regexp"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	renamePrompt     = "rename"
+	bulkRenamePrompt = "bulk-rename"
+	renameTask       = "rename"
+)
+
+// Rename targets collected when the rename prompt is opened
+type pendingRename struct {
+	keys   []string
+	prefix string
+}
+
+// Shown as a table before a bulk rename is executed
+type renamePreview struct {
+	table      *table.Model
+	items      []api.CopyItem
+	collisions int
+}
+
+type renameCheckedMsg struct {
+	item   api.CopyItem
+	exists bool
+	err    error
+}
+
+type renamePlanMsg struct {
+	items      []api.CopyItem
+	collisions []bool
+	err        error
+}
+
+type renameResult struct {
+	renamed      int
+	deleteFailed []api.DeleteError
+}
+
+func handleRenameKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil || isDirectoryRow(*r) {
+		return
+	}
+
+	model.pendingRename = &pendingRename{keys: []string{(*r)[1]}}
+	openPrompt(prompt.NewInput(renamePrompt, "Rename", fmt.Sprintf("New key for %s:", getS3Uri(m, (*r)[1])), "new key", (*r)[1]), cmds)
+}
+
+func handleBulkRenameKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	pr := &pendingRename{keys: getSelectedKeys()}
+	if len(pr.keys) == 0 {
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+
+		if isDirectoryRow(*r) {
+			pr.prefix = (*r)[1]
+		} else {
+			pr.keys = []string{(*r)[1]}
+		}
+	}
+	model.pendingRename = pr
+
+	body := fmt.Sprintf("Apply a regular expression to the %v selected keys.", len(pr.keys))
+	if pr.prefix != "" {
+		body = fmt.Sprintf("Apply a regular expression to every key under %s.", getS3Uri(m, pr.prefix))
+	}
+	body += " Use $1, ${name} in the replacement to refer to groups."
+
+	fields := []prompt.Field{
+		{Label: "Find", Placeholder: "regular expression"},
+		{Label: "Replace", Placeholder: "replacement"},
+	}
+	openPrompt(prompt.New(bulkRenamePrompt, "Bulk rename", body, fields, nil), cmds)
+}
+
+func handleRenameConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pr := model.pendingRename
+	if pr == nil {
+		closePrompt()
+		return
+	}
+
+	if msg.Id == renamePrompt {
+		newKey := strings.TrimSpace(msg.Values[0])
+		if newKey == "" || newKey == pr.keys[0] {
+			model.prompt.SetError("enter a different key")
+			return
+		}
+
+		closePrompt()
+		item := api.CopyItem{
+			SourceBucket: m.GetCurrentBucket(),
+			SourceKey:    pr.keys[0],
+			Size:         getFileSizes()[pr.keys[0]],
+			DestBucket:   m.GetCurrentBucket(),
+			DestKey:      newKey,
+		}
+		showLoading(fmt.Sprintf("Checking %s", newKey), cmds)
+		*cmds = append(*cmds, func() tea.Msg {
+			exists, err := api.ObjectExists(m.Session, item.DestBucket, item.DestKey)
+			return renameCheckedMsg{item, exists, err}
+		})
+		return
+	}
+
+	find, err := regexp.Compile(msg.Values[0])
+	if err != nil || msg.Values[0] == "" {
+		model.prompt.SetError("enter a valid regular expression")
+		return
+	}
+	replace := msg.Values[1]
+
+	closePrompt()
+	model.pendingRename = nil
+	bucket := m.GetCurrentBucket()
+	objects := model.files
+
+	if pr.prefix == "" {
+		showLoading("Checking the new keys", cmds)
+	} else {
+		showLoading(fmt.Sprintf("Listing %s", pr.prefix), cmds)
+	}
+	*cmds = append(*cmds, func() tea.Msg {
+		targets := make(map[string]bool)
+		for _, k := range pr.keys {
+			targets[k] = true
+		}
+		if pr.prefix != "" {
+			var err error
+			if objects, err = api.GetAllObjects(m.Session, bucket, pr.prefix); err != nil {
+				return renamePlanMsg{err: err}
+			}
+			for _, o := range objects {
+				targets[*o.Key] = true
+			}
+		}
+
+		items := getRenameItems(bucket, objects, targets, find, replace)
+
+		// New keys outside of the listed objects are looked up one by one
+		existing := make(map[string]bool)
+		for _, o := range objects {
+			existing[*o.Key] = true
+		}
+		for _, item := range items {
+			if _, ok := existing[item.DestKey]; ok {
+				continue
+			}
+			exists, err := api.ObjectExists(m.Session, bucket, item.DestKey)
+			if err != nil {
+				return renamePlanMsg{err: fmt.Errorf("checking %s: %w", item.DestKey, err)}
+			}
+			existing[item.DestKey] = exists
+		}
+
+		return renamePlanMsg{items, findRenameCollisions(items, existing), nil}
+	})
+}
+
+// The objects of targets the expression changes, as copies from the old key to the new one
+func getRenameItems(bucket string, objects []*s3.Object, targets map[string]bool, find *regexp.Regexp, replace string) []api.CopyItem {
+	items := make([]api.CopyItem, 0)
+	for _, o := range objects {
+		if !targets[*o.Key] || !find.MatchString(*o.Key) {
+			continue
+		}
+
+		newKey := find.ReplaceAllString(*o.Key, replace)
+		if newKey == *o.Key {
+			continue
+		}
+
+		items = append(items, api.CopyItem{
+			SourceBucket: bucket,
+			SourceKey:    *o.Key,
+			Size:         *o.Size,
+			DestBucket:   bucket,
+			DestKey:      newKey,
+		})
+	}
+
+	return items
+}
+
+// Marks the items whose new key is also the new key of another item, the old key of another item or an
+// existing object of the destination bucket.  Every copy is done before the old keys are deleted, so
+// any of these loses data.  Copies and moves use it as well, keys are compared along with their bucket.
+func findRenameCollisions(items []api.CopyItem, existing map[string]bool) []bool {
+	newKeys := make(map[string]int)
+	oldKeys := make(map[string]bool)
+	for _, item := range items {
+		newKeys[item.DestBucket+"/"+item.DestKey]++
+		oldKeys[item.SourceBucket+"/"+item.SourceKey] = true
+	}
+
+	collisions := make([]bool, len(items))
+	for i, item := range items {
+		dest := item.DestBucket + "/" + item.DestKey
+		collisions[i] = newKeys[dest] > 1 || oldKeys[dest] || existing[item.DestKey]
+	}
+
+	return collisions
+}
+
+// An existing object at the new key is never overwritten, the prompt is opened again instead
+func handleRenameCheckedMsg(m *types.UiModel, msg renameCheckedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		model.pendingRename = nil
+		openPrompt(prompt.NewMessage("", "Rename failed", msg.err.Error()), cmds)
+		return
+	}
+
+	if msg.exists {
+		body := fmt.Sprintf("New key for %s:", getS3Uri(m, msg.item.SourceKey))
+		openPrompt(prompt.NewInput(renamePrompt, "Rename", body, "new key", msg.item.DestKey), cmds)
+		model.prompt.SetError(fmt.Sprintf("%s already exists", msg.item.DestKey))
+		return
+	}
+
+	model.pendingRename = nil
+	*cmds = append(*cmds, runRename(m, []api.CopyItem{msg.item}))
+}
+
+// Shows the preview of old key -> new key, collisions block the rename
+func handleRenamePlanMsg(m *types.UiModel, msg renamePlanMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Rename failed", msg.err.Error()), cmds)
+		return
+	}
+
+	if len(msg.items) == 0 {
+		openPrompt(prompt.NewMessage("", "Bulk rename", "The expression does not change any of the keys."), cmds)
+		return
+	}
+
+	preview := &renamePreview{
+		items: msg.items,
+		table: table.New([]table.Column{
+			{Name: "", Width: 3},
+			{Name: "Old Key", Width: 55, ShowFullPath: true},
+			{Name: "New Key", Width: 55, ShowFullPath: true},
+			{Name: "Status", Width: 15},
+		}, false),
+	}
+
+	rows := make([]table.Row, len(msg.items))
+	for i, item := range msg.items {
+		status := "ok"
+		if msg.collisions[i] {
+			status = "⚠ collision"
+			preview.collisions++
+		}
+		rows[i] = table.Row{"→", item.SourceKey, item.DestKey, status}
+	}
+	preview.table.SetData(rows)
+	preview.table.SetFooterInfo(fmt.Sprintf("%v keys, %v collisions", len(msg.items), preview.collisions))
+	model.renamePreview = preview
+}
+
+func handleRenamePreviewKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		model.renamePreview = nil
+
+	case "enter":
+		if model.renamePreview.collisions > 0 {
+			openPrompt(prompt.NewMessage("", "Bulk rename", "Resolve the collisions before renaming, no keys were changed."), cmds)
+			return
+		}
+
+		items := model.renamePreview.items
+		model.renamePreview = nil
+		*cmds = append(*cmds, runRename(m, items))
+
+	default:
+		var cmd tea.Cmd
+		model.renamePreview.table, cmd = model.renamePreview.table.Update(msg)
+		*cmds = append(*cmds, cmd)
+	}
+}
+
+// S3 has no rename, every key is copied to its new name and the old keys are deleted afterwards
+func runRename(m *types.UiModel, items []api.CopyItem) tea.Cmd {
+	return task.Run(renameTask, func(report task.Reporter) (interface{}, error) {
+		result := renameResult{}
+		n := int64(len(items))
+		done := int64(-1)
+		current := ""
+
+		// Empty options keep the metadata, tags, storage class and encryption of every object
+		_, err := api.CopyObjects(m.Session, items, api.CopyOptions{}, func(c int64, i api.CopyItem) {
+			if i.SourceKey != current {
+				current = i.SourceKey
+				done++
+			}
+			report(done, n, fmt.Sprintf("Renaming %s", i.SourceKey))
+		})
+		if err != nil {
+			return result, err
+		}
+		result.renamed = len(items)
+
+		keys := make([]string, len(items))
+		for i, item := range items {
+			keys[i] = item.SourceKey
+		}
+		report(n, n, "Removing old keys")
+		result.deleteFailed, err = api.DeleteObjects(m.Session, m.GetCurrentBucket(), keys, nil)
+
+		return result, err
+	})
+}
+
+func handleRenameDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(renameResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Rename failed", msg.Err.Error()), cmds)
+		return
+	}
+
+	if len(r.deleteFailed) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Renamed %v objects, %v old keys could not be removed:\n", r.renamed, len(r.deleteFailed))
+		utils.WriteFailures(&b, r.deleteFailed)
+		openPrompt(prompt.NewMessage("", "Rename finished with errors", b.String()), cmds)
+		return
+	}
+
+	if r.renamed > 1 {
+		openPrompt(prompt.NewMessage("", "Rename finished", fmt.Sprintf("Renamed %v objects.", r.renamed)), cmds)
+	}
+}
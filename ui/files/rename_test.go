@@ -0,0 +1,77 @@
+package files
+
+import (
+	"reflect"
+	"regexp"
+	"s3-viewer/api"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func renameItem(from, to string) api.CopyItem {
+	return api.CopyItem{SourceBucket: "b", SourceKey: from, DestBucket: "b", DestKey: to}
+}
+
+func TestGetRenameItems(t *testing.T) {
+	objects := []*s3.Object{
+		{Key: aws.String("x/a.txt"), Size: aws.Int64(1)},
+		{Key: aws.String("x/b.txt"), Size: aws.Int64(2)},
+		{Key: aws.String("x/c.log"), Size: aws.Int64(3)},
+	}
+	targets := map[string]bool{"x/a.txt": true, "x/c.log": true}
+
+	items := getRenameItems("b", objects, targets, regexp.MustCompile(`\.txt$`), ".md")
+	want := []api.CopyItem{{SourceBucket: "b", SourceKey: "x/a.txt", Size: 1, DestBucket: "b", DestKey: "x/a.md"}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("got %+v, want %+v", items, want)
+	}
+}
+
+func TestFindRenameCollisions(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []api.CopyItem
+		existing map[string]bool
+		want     []bool
+	}{
+		{
+			name:  "distinct new keys",
+			items: []api.CopyItem{renameItem("x/a", "y/a"), renameItem("x/b", "y/b")},
+			want:  []bool{false, false},
+		},
+		{
+			name:  "same new key twice",
+			items: []api.CopyItem{renameItem("x/a", "y/a"), renameItem("x/b", "y/a")},
+			want:  []bool{true, true},
+		},
+		{
+			name:     "existing object",
+			items:    []api.CopyItem{renameItem("x/a", "y/a"), renameItem("x/b", "y/b")},
+			existing: map[string]bool{"x/a": true, "x/b": true, "y/b": true},
+			want:     []bool{false, true},
+		},
+		{
+			name:     "new key is the old key of another item",
+			items:    []api.CopyItem{renameItem("x/a", "x/b"), renameItem("x/aa", "x/a")},
+			existing: map[string]bool{"x/a": true, "x/aa": true},
+			want:     []bool{false, true},
+		},
+		{
+			name:     "swap",
+			items:    []api.CopyItem{renameItem("x/a", "x/b"), renameItem("x/b", "x/a")},
+			existing: map[string]bool{"x/a": true, "x/b": true},
+			want:     []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findRenameCollisions(tt.items, tt.existing)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/dialog"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/qr"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	sharePrompt       = "share"
+	shareResultPrompt = "share-result"
+
+	shareGet  = "GET url"
+	sharePut  = "PUT url"
+	sharePost = "POST policy"
+
+	shareOk     = "Ok"
+	shareQrCode = "QR code"
+)
+
+var (
+	qrCaptionStyle = lipgloss.NewStyle().Width(50).Align(lipgloss.Center).MarginTop(1)
+	qrWarningStyle = qrCaptionStyle.Copy().Foreground(lipgloss.Color("#ff4754"))
+
+	shareExpiries = []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"15 minutes", 15 * time.Minute},
+		{"1 hour", time.Hour},
+		{"12 hours", 12 * time.Hour},
+		{"1 day", 24 * time.Hour},
+		{"7 days", api.MaxPresignExpiry},
+	}
+)
+
+type shareMsg struct {
+	kind    string
+	url     string
+	expiry  string
+	err     error
+	copyErr error
+}
+
+func handleShareKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil {
+		return
+	}
+
+	key := (*r)[1]
+	kinds := []string{shareGet, sharePut, sharePost}
+	body := fmt.Sprintf("Create a presigned link for %s", getS3Uri(m, key))
+	// A folder can only be shared as an upload location
+	if isDirectoryRow(*r) {
+		kinds = []string{sharePost}
+		body = fmt.Sprintf("Create an upload policy for anything under %s", getS3Uri(m, key))
+	}
+
+	expiries := make([]string, len(shareExpiries))
+	for i, e := range shareExpiries {
+		expiries[i] = e.name
+	}
+
+	fields := []prompt.Field{
+		{Label: "Type", Options: kinds},
+		{Label: "Expires in", Options: expiries, Value: "1 hour"},
+		{Label: "Version id", Placeholder: "latest (GET only)"},
+		{Label: "Content-Disposition", Placeholder: "attachment; filename=... (GET only)"},
+		{Label: "Content-Type", Placeholder: "any (PUT only)"},
+	}
+
+	model.shareKey = key
+	openPrompt(prompt.New(sharePrompt, "Share", body, fields, nil), cmds)
+}
+
+func handleShareConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	kind := msg.Values[0]
+	versionId := strings.TrimSpace(msg.Values[2])
+	disposition := strings.TrimSpace(msg.Values[3])
+	contentType := strings.TrimSpace(msg.Values[4])
+
+	var expiry time.Duration
+	for _, e := range shareExpiries {
+		if e.name == msg.Values[1] {
+			expiry = e.duration
+		}
+	}
+
+	if kind != shareGet && (versionId != "" || disposition != "") {
+		model.prompt.SetError("version id and Content-Disposition only apply to GET urls")
+		return
+	}
+	if kind != sharePut && contentType != "" {
+		model.prompt.SetError("Content-Type only applies to PUT urls")
+		return
+	}
+
+	closePrompt()
+	key := model.shareKey
+	bucket := m.GetCurrentBucket()
+	showLoading("Signing", cmds)
+
+	*cmds = append(*cmds, func() tea.Msg {
+		var url string
+		var err error
+
+		switch kind {
+		case shareGet:
+			url, err = api.PresignGet(m.Session, bucket, key, versionId, disposition, expiry)
+		case sharePut:
+			url, err = api.PresignPut(m.Session, bucket, key, contentType, expiry)
+		case sharePost:
+			prefix := key
+			if !isDirectoryKey(key) {
+				prefix = m.GetCurrentPath()
+			}
+			var p *api.PresignedPost
+			if p, err = api.PresignPost(m.Session, bucket, prefix, expiry); err == nil {
+				url = getCurlCommand(p)
+			}
+		}
+
+		if err != nil {
+			return shareMsg{err: err}
+		}
+
+		return shareMsg{kind: kind, url: url, expiry: msg.Values[1], copyErr: utils.CopyToClipboard(url)}
+	})
+}
+
+func handleShareMsg(m *types.UiModel, msg shareMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Share failed", msg.err.Error()), cmds)
+		return
+	}
+
+	copied := "Copied to the clipboard."
+	if msg.copyErr != nil {
+		copied = fmt.Sprintf("Could not copy to the clipboard: %s", msg.copyErr)
+	}
+
+	body := fmt.Sprintf("%s, valid for %s. %s\n\n%s", msg.kind, msg.expiry, copied, msg.url)
+
+	// The curl command of a POST policy is of no use on a phone so there is no QR code for it
+	options := []string{shareOk}
+	if msg.kind != sharePost {
+		options = append(options, shareQrCode)
+		model.shareUrl = msg.url
+	}
+	openPrompt(prompt.New(shareResultPrompt, "Share", body, nil, options), cmds)
+}
+
+func handleShareResultConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	closePrompt()
+	if msg.Option != 1 {
+		model.shareUrl = ""
+		return
+	}
+
+	code, warning, err := qr.Render(model.shareUrl)
+	model.shareUrl = ""
+	if err != nil {
+		openPrompt(prompt.NewMessage("", "QR code", err.Error()), cmds)
+		return
+	}
+
+	content := []string{code, qrCaptionStyle.Render("Scan to open the link • any key to close")}
+	if warning != "" {
+		content = append(content, qrWarningStyle.Render(fmt.Sprintf("⚠ %s", warning)))
+	}
+	model.qrCode = dialog.DialogBoxStyle.Copy().Padding(1, 2).Render(lipgloss.JoinVertical(lipgloss.Center, content...))
+}
+
+// A POST policy is handed over as a curl command since the form fields have to be sent as well
+func getCurlCommand(p *api.PresignedPost) string {
+	names := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("curl")
+	for _, n := range names {
+		fmt.Fprintf(&b, " -F '%s=%s'", n, p.Fields[n])
+	}
+	// The file has to be the last field of the form
+	fmt.Fprintf(&b, " -F 'file=@<path>' %s", p.Url)
+
+	return b.String()
+}
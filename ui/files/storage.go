@@ -0,0 +1,336 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	storageClassColumn = 3
+
+	storageClassPrompt = "storage-class"
+	storageClassTask   = "storage-class"
+	restorePrompt      = "restore"
+	restoreTask        = "restore"
+
+	// The restore status needs a HEAD per object, a page full of archived objects is only partly checked
+	maxRestoreChecks = 100
+)
+
+var (
+	archivedColor = lipgloss.Color("#5CC1F7")
+	restoredColor = lipgloss.Color("#8EC07C")
+	coolColor     = lipgloss.Color("245")
+)
+
+// Objects a storage class change or restore applies to, with their sizes and classes from the listing
+type pendingStorage struct {
+	objects []*s3.Object
+}
+
+type restoreStatusMsg struct {
+	bucket   string
+	path     string
+	statuses map[string]*api.RestoreStatus
+}
+
+type storageResult struct {
+	changed int
+	skipped int
+	failed  []string
+}
+
+// Archive tiers stand out, restored copies of them are shown as readable again
+func storageClassColor(v string) lipgloss.TerminalColor {
+	class, status, _ := strings.Cut(v, " ")
+	switch {
+	case status == "✓":
+		return restoredColor
+	case api.IsArchived(class):
+		return archivedColor
+	case class != "" && class != s3.StorageClassStandard:
+		return coolColor
+	}
+
+	return nil
+}
+
+func getStorageClassCell(class string, status *api.RestoreStatus) string {
+	switch {
+	case status == nil || !api.IsArchived(class):
+		return class
+	case status.Ongoing:
+		return class + " ⧗"
+	default:
+		return class + " ✓"
+	}
+}
+
+func findFile(key string) *s3.Object {
+	for _, f := range model.files {
+		if *f.Key == key {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// The listing does not include the restore status so archived objects of the page are checked one by one
+func checkRestoreStatus(m *types.UiModel, cmds *[]tea.Cmd) {
+	keys := make([]string, 0)
+	for _, f := range model.files {
+		if api.IsArchived(aws.StringValue(f.StorageClass)) && len(keys) < maxRestoreChecks {
+			keys = append(keys, *f.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	bucket := m.GetCurrentBucket()
+	path := m.GetCurrentPath()
+	*cmds = append(*cmds, func() tea.Msg {
+		statuses := make(map[string]*api.RestoreStatus)
+		for _, k := range keys {
+			// A failed check leaves the object shown as archived
+			if s, err := api.GetRestoreStatus(m.Session, bucket, k); err == nil && s != nil {
+				statuses[k] = s
+			}
+		}
+		return restoreStatusMsg{bucket, path, statuses}
+	})
+}
+
+func handleRestoreStatusMsg(m *types.UiModel, msg restoreStatusMsg) {
+	if msg.bucket != m.GetCurrentBucket() || msg.path != m.GetCurrentPath() || model.archive != nil {
+		return
+	}
+
+	for k, s := range msg.statuses {
+		model.restoreStatus[k] = s
+	}
+	model.table.UpdateRows(func(r table.Row) {
+		if s, ok := msg.statuses[r[1]]; ok {
+			class, _, _ := strings.Cut(r[storageClassColumn], " ")
+			r[storageClassColumn] = getStorageClassCell(class, s)
+		}
+	})
+}
+
+// The selected objects or the highlighted one
+func getStorageTargets() []*s3.Object {
+	objects := make([]*s3.Object, 0)
+	for _, k := range getSelectedKeys() {
+		if f := findFile(k); f != nil {
+			objects = append(objects, f)
+		}
+	}
+	if len(objects) > 0 {
+		return objects
+	}
+
+	r := model.table.GetHighlightedRow()
+	if r == nil || isDirectoryRow(*r) {
+		return objects
+	}
+	if f := findFile((*r)[1]); f != nil {
+		objects = append(objects, f)
+	}
+
+	return objects
+}
+
+func handleStorageClassKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	objects := getStorageTargets()
+	if len(objects) == 0 {
+		return
+	}
+	model.pendingStorage = &pendingStorage{objects}
+
+	body := fmt.Sprintf("Move the %v selected objects to another storage class.", len(objects))
+	class := s3.StorageClassStandard
+	if len(objects) == 1 {
+		class = aws.StringValue(objects[0].StorageClass)
+		body = fmt.Sprintf("Move %s to another storage class, it is in %s now.", getS3Uri(m, *objects[0].Key), class)
+	}
+	body += " Objects are copied onto themselves, archived objects have to be restored first."
+
+	fields := []prompt.Field{{Label: "Storage class", Value: class, Options: api.StorageClasses}}
+	openPrompt(prompt.New(storageClassPrompt, "Storage class", body, fields, nil), cmds)
+}
+
+func handleStorageClassConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	ps := model.pendingStorage
+	closePrompt()
+	model.pendingStorage = nil
+	if ps == nil {
+		return
+	}
+
+	class := msg.Values[0]
+	bucket := m.GetCurrentBucket()
+	*cmds = append(*cmds, task.Run(storageClassTask, func(report task.Reporter) (interface{}, error) {
+		result := storageResult{}
+		var total, done int64
+		for _, o := range ps.objects {
+			total += *o.Size
+		}
+
+		for _, o := range ps.objects {
+			if aws.StringValue(o.StorageClass) == class {
+				result.skipped++
+				continue
+			}
+
+			report(done, total, fmt.Sprintf("Moving %s to %s", *o.Key, class))
+			err := api.ChangeStorageClass(m.Session, bucket, *o.Key, *o.Size, class)
+			done += *o.Size
+			if err != nil {
+				result.failed = append(result.failed, fmt.Sprintf("%s: %s", *o.Key, err))
+				continue
+			}
+			result.changed++
+		}
+
+		return result, nil
+	}))
+}
+
+func handleStorageClassDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(storageResult)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Moved %v objects", r.changed)
+	if r.skipped > 0 {
+		fmt.Fprintf(&b, ", %v were already in that class", r.skipped)
+	}
+	b.WriteString(".")
+
+	if len(r.failed) > 0 {
+		fmt.Fprintf(&b, "\n\n%v could not be moved:\n", len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Storage class changed with errors", b.String()), cmds)
+		return
+	}
+
+	openPrompt(prompt.NewMessage("", "Storage class changed", b.String()), cmds)
+}
+
+// Only GLACIER and DEEP_ARCHIVE objects are restored, the rest of the selection is left out
+func handleRestoreKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	objects := make([]*s3.Object, 0)
+	for _, o := range getStorageTargets() {
+		if api.IsArchived(aws.StringValue(o.StorageClass)) {
+			objects = append(objects, o)
+		}
+	}
+	if len(objects) == 0 {
+		openPrompt(prompt.NewMessage("", "Restore", "Only GLACIER and DEEP_ARCHIVE objects have to be restored."), cmds)
+		return
+	}
+	model.pendingStorage = &pendingStorage{objects}
+
+	body := fmt.Sprintf("Restore a readable copy of the %v archived objects.", len(objects))
+	if len(objects) == 1 {
+		body = fmt.Sprintf("Restore a readable copy of %s.", getS3Uri(m, *objects[0].Key))
+		switch s := model.restoreStatus[*objects[0].Key]; {
+		case s != nil && s.Ongoing:
+			body += "\n\nA restore is already in progress."
+		case s != nil && !s.Expiry.IsZero():
+			body += fmt.Sprintf("\n\nRestored until %s, restoring again changes how long it is kept.", s.Expiry.Local().Format(time.DateTime))
+		}
+	}
+
+	fields := []prompt.Field{
+		{Label: "Tier", Value: s3.TierStandard, Options: api.RestoreTiers},
+		{Label: "Days", Placeholder: "days the copy is kept", Value: "7"},
+	}
+	openPrompt(prompt.New(restorePrompt, "Restore", body, fields, nil), cmds)
+}
+
+func handleRestoreConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	ps := model.pendingStorage
+	if ps == nil {
+		closePrompt()
+		return
+	}
+
+	tier := msg.Values[0]
+	days, err := strconv.ParseInt(strings.TrimSpace(msg.Values[1]), 10, 64)
+	if err != nil || days < 1 {
+		model.prompt.SetError("days has to be a number of at least 1")
+		return
+	}
+	if tier == s3.TierExpedited {
+		for _, o := range ps.objects {
+			if aws.StringValue(o.StorageClass) == s3.StorageClassDeepArchive {
+				model.prompt.SetError("DEEP_ARCHIVE objects can not be restored with the Expedited tier")
+				return
+			}
+		}
+	}
+
+	closePrompt()
+	model.pendingStorage = nil
+	bucket := m.GetCurrentBucket()
+	*cmds = append(*cmds, task.Run(restoreTask, func(report task.Reporter) (interface{}, error) {
+		result := storageResult{}
+		n := int64(len(ps.objects))
+		for i, o := range ps.objects {
+			report(int64(i), n, fmt.Sprintf("Restoring %s", *o.Key))
+			err := api.RestoreObject(m.Session, bucket, *o.Key, tier, days)
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "RestoreAlreadyInProgress" {
+				result.skipped++
+				continue
+			}
+			if err != nil {
+				result.failed = append(result.failed, fmt.Sprintf("%s: %s", *o.Key, err))
+				continue
+			}
+			result.changed++
+		}
+
+		return result, nil
+	}))
+}
+
+func handleRestoreDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+	refreshFiles(m, cmds)
+
+	r, _ := msg.Result.(storageResult)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Requested the restore of %v objects", r.changed)
+	if r.skipped > 0 {
+		fmt.Fprintf(&b, ", %v were already being restored", r.skipped)
+	}
+	b.WriteString(". Restored objects are marked with ✓, ones in progress with ⧗.")
+
+	if len(r.failed) > 0 {
+		fmt.Fprintf(&b, "\n\n%v could not be restored:\n", len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Restore requested with errors", b.String()), cmds)
+		return
+	}
+
+	openPrompt(prompt.NewMessage("", "Restore requested", b.String()), cmds)
+}
@@ -0,0 +1,452 @@
+package files
+
+import (
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/prompt"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	tagPrompt      = "tag"
+	bulkTagsPrompt = "bulk-tags"
+	tagsScanTask   = "tags-scan"
+	tagsTask       = "tags"
+)
+
+// The tags of one object.  Every change is saved right away.
+type tagsPanel struct {
+	key     string
+	tags    map[string]string
+	table   *table.Model
+	editing string // Tag being edited in the prompt, empty when one is added
+}
+
+// Objects the bulk tagging prompt is going to change.  Either a list of selected keys or a prefix.
+type pendingTags struct {
+	keys   []string
+	prefix string
+}
+
+type tagsChange struct {
+	add    map[string]string
+	remove []string
+}
+
+// An object with the tags it is going to get
+type tagsUpdate struct {
+	key     string
+	tags    map[string]string
+	changes []string
+}
+
+// The dry run of a bulk tagging, applied from here
+type tagsPreview struct {
+	table     *table.Model
+	updates   []tagsUpdate
+	overLimit int
+}
+
+type tagsLoadedMsg struct {
+	key  string
+	tags map[string]string
+	err  error
+}
+
+type tagsSavedMsg struct {
+	key  string
+	tags map[string]string
+	err  error
+}
+
+type tagsScanResult struct {
+	updates   []tagsUpdate
+	unchanged int
+}
+
+type tagsResult struct {
+	changed int
+	failed  []string
+}
+
+// Opens the tags of the highlighted object, or bulk tagging for the selection or the highlighted folder
+func handleTagsKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	pt := &pendingTags{keys: getSelectedKeys()}
+	if len(pt.keys) == 0 {
+		r := model.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+
+		if !isDirectoryRow(*r) {
+			key := (*r)[1]
+			bucket := m.GetCurrentBucket()
+			showLoading(fmt.Sprintf("Reading the tags of %s", key), cmds)
+			*cmds = append(*cmds, func() tea.Msg {
+				tags, err := api.GetObjectTags(m.Session, bucket, key)
+				return tagsLoadedMsg{key, tags, err}
+			})
+			return
+		}
+		pt.prefix = (*r)[1]
+	}
+	model.pendingTags = pt
+
+	body := fmt.Sprintf("Add or remove tags on the %v selected objects.", len(pt.keys))
+	if pt.prefix != "" {
+		body = fmt.Sprintf("Add or remove tags on every object under %s.", getS3Uri(m, pt.prefix))
+	}
+	body += " Nothing is changed until the dry run is confirmed."
+
+	fields := []prompt.Field{
+		{Label: "Add tags", Placeholder: "key=value, ..."},
+		{Label: "Remove tags", Placeholder: "key, ..."},
+	}
+	openPrompt(prompt.New(bulkTagsPrompt, "Tags", body, fields, nil), cmds)
+}
+
+func handleTagsLoadedMsg(m *types.UiModel, msg tagsLoadedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Tags", msg.err.Error()), cmds)
+		return
+	}
+
+	model.tagsPanel = &tagsPanel{
+		key: msg.key,
+		table: table.New([]table.Column{
+			{Name: "Tag", Width: 40, PlainText: true},
+			{Name: "Value", Width: 70, PlainText: true},
+		}, false),
+	}
+	model.tagsPanel.setTags(msg.tags)
+}
+
+func (p *tagsPanel) setTags(tags map[string]string) {
+	p.tags = tags
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rows := make([]table.Row, len(keys))
+	for i, k := range keys {
+		rows[i] = table.Row{k, tags[k]}
+	}
+	p.table.SetData(rows)
+	p.table.SetFooterInfo(fmt.Sprintf("%s • %v of %v tags", p.key, len(tags), api.MaxObjectTags))
+}
+
+func handleTagsPanelKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
+	p := model.tagsPanel
+	switch msg.String() {
+	case "esc":
+		model.tagsPanel = nil
+
+	case "a":
+		if len(p.tags) >= api.MaxObjectTags {
+			body := fmt.Sprintf("An object can have at most %v tags", api.MaxObjectTags)
+			openPrompt(prompt.NewMessage("", "Too many tags", body), cmds)
+			return
+		}
+		p.editing = ""
+		openTagPrompt("Add tag", "", "", cmds)
+
+	case "enter", "e":
+		r := p.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+		p.editing = (*r)[0]
+		openTagPrompt("Edit tag", (*r)[0], (*r)[1], cmds)
+
+	case "d":
+		r := p.table.GetHighlightedRow()
+		if r == nil {
+			return
+		}
+		tags := copyTags(p.tags)
+		delete(tags, (*r)[0])
+		saveTags(m, tags, cmds)
+
+	default:
+		var cmd tea.Cmd
+		p.table, cmd = p.table.Update(msg)
+		*cmds = append(*cmds, cmd)
+	}
+}
+
+func openTagPrompt(title, key, value string, cmds *[]tea.Cmd) {
+	fields := []prompt.Field{
+		{Label: "Tag", Placeholder: "key", Value: key},
+		{Label: "Value", Placeholder: "can be empty", Value: value},
+	}
+	openPrompt(prompt.New(tagPrompt, title, model.tagsPanel.key, fields, nil), cmds)
+}
+
+// Renaming a tag replaces the old one
+func handleTagConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	p := model.tagsPanel
+	if p == nil {
+		closePrompt()
+		return
+	}
+
+	key := strings.TrimSpace(msg.Values[0])
+	if key == "" {
+		model.prompt.SetError("the tag needs a key")
+		return
+	}
+	if _, ok := p.tags[key]; ok && key != p.editing {
+		model.prompt.SetError(fmt.Sprintf("%s is already set", key))
+		return
+	}
+
+	closePrompt()
+	tags := copyTags(p.tags)
+	delete(tags, p.editing)
+	tags[key] = strings.TrimSpace(msg.Values[1])
+	saveTags(m, tags, cmds)
+}
+
+func saveTags(m *types.UiModel, tags map[string]string, cmds *[]tea.Cmd) {
+	key := model.tagsPanel.key
+	bucket := m.GetCurrentBucket()
+	showLoading(fmt.Sprintf("Saving the tags of %s", key), cmds)
+	*cmds = append(*cmds, func() tea.Msg {
+		return tagsSavedMsg{key, tags, api.PutObjectTags(m.Session, bucket, key, tags)}
+	})
+}
+
+func handleTagsSavedMsg(m *types.UiModel, msg tagsSavedMsg, cmds *[]tea.Cmd) {
+	model.loadingMessage = ""
+	if msg.err != nil {
+		openPrompt(prompt.NewMessage("", "Could not save the tags", msg.err.Error()), cmds)
+		return
+	}
+
+	if model.tagsPanel != nil && model.tagsPanel.key == msg.key {
+		model.tagsPanel.setTags(msg.tags)
+	}
+}
+
+func copyTags(tags map[string]string) map[string]string {
+	c := make(map[string]string, len(tags))
+	for k, v := range tags {
+		c[k] = v
+	}
+
+	return c
+}
+
+func handleBulkTagsConfirmed(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	pt := model.pendingTags
+	if pt == nil {
+		closePrompt()
+		return
+	}
+
+	change, err := parseTagsChange(msg)
+	if err != nil {
+		model.prompt.SetError(err.Error())
+		return
+	}
+
+	closePrompt()
+	model.pendingTags = nil
+	bucket := m.GetCurrentBucket()
+
+	*cmds = append(*cmds, task.Run(tagsScanTask, func(report task.Reporter) (interface{}, error) {
+		result := tagsScanResult{}
+
+		keys := pt.keys
+		if pt.prefix != "" {
+			report(0, 0, fmt.Sprintf("Listing %s", pt.prefix))
+			objects, err := api.GetAllObjects(m.Session, bucket, pt.prefix)
+			if err != nil {
+				return result, err
+			}
+			keys = make([]string, 0, len(objects))
+			for _, o := range objects {
+				if !isDirectoryKey(*o.Key) {
+					keys = append(keys, *o.Key)
+				}
+			}
+		}
+
+		n := int64(len(keys))
+		for i, k := range keys {
+			report(int64(i), n, fmt.Sprintf("Reading the tags of %s", k))
+			tags, err := api.GetObjectTags(m.Session, bucket, k)
+			if err != nil {
+				return result, fmt.Errorf("reading %s: %w", k, err)
+			}
+
+			u := change.apply(k, tags)
+			if len(u.changes) == 0 {
+				result.unchanged++
+				continue
+			}
+			result.updates = append(result.updates, u)
+		}
+
+		return result, nil
+	}))
+}
+
+func parseTagsChange(msg prompt.SubmittedMsg) (tagsChange, error) {
+	c := tagsChange{}
+
+	var err error
+	if c.add, err = utils.ParseKeyValues(msg.Values[0]); err != nil {
+		return c, err
+	}
+	for _, k := range strings.Split(msg.Values[1], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			c.remove = append(c.remove, k)
+		}
+	}
+	if len(c.add) == 0 && len(c.remove) == 0 {
+		return c, fmt.Errorf("fill in at least one field")
+	}
+
+	return c, nil
+}
+
+func (c tagsChange) apply(key string, current map[string]string) tagsUpdate {
+	u := tagsUpdate{key: key, tags: copyTags(current)}
+	for _, k := range c.remove {
+		if v, ok := u.tags[k]; ok {
+			delete(u.tags, k)
+			u.changes = append(u.changes, fmt.Sprintf("-%s=%s", k, v))
+		}
+	}
+
+	keys := make([]string, 0, len(c.add))
+	for k := range c.add {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		old, ok := u.tags[k]
+		switch {
+		case !ok:
+			u.changes = append(u.changes, fmt.Sprintf("+%s=%s", k, c.add[k]))
+		case old != c.add[k]:
+			u.changes = append(u.changes, fmt.Sprintf("%s: %s → %s", k, old, c.add[k]))
+		}
+		u.tags[k] = c.add[k]
+	}
+
+	return u
+}
+
+func handleTagsScanDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	r, _ := msg.Result.(tagsScanResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Tagging failed", msg.Err.Error()), cmds)
+		return
+	}
+	if len(r.updates) == 0 {
+		openPrompt(prompt.NewMessage("", "Tags", fmt.Sprintf("None of the %v objects would change.", r.unchanged)), cmds)
+		return
+	}
+
+	preview := &tagsPreview{
+		updates: r.updates,
+		table: table.New([]table.Column{
+			{Name: "Key", Width: 50, ShowFullPath: true},
+			{Name: "Changes", Width: 70, PlainText: true},
+			{Name: "Status", Width: 15},
+		}, false),
+	}
+
+	rows := make([]table.Row, len(r.updates))
+	for i, u := range r.updates {
+		status := "ok"
+		if len(u.tags) > api.MaxObjectTags {
+			status = "⚠ too many"
+			preview.overLimit++
+		}
+		rows[i] = table.Row{u.key, strings.Join(u.changes, ", "), status}
+	}
+	preview.table.SetData(rows)
+	preview.table.SetFooterInfo(fmt.Sprintf(
+		"dry run: %v objects change, %v unchanged, %v over %v tags",
+		len(r.updates), r.unchanged, preview.overLimit, api.MaxObjectTags))
+	model.tagsPreview = preview
+}
+
+func handleTagsPreviewKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		model.tagsPreview = nil
+
+	case "enter":
+		if model.tagsPreview.overLimit > 0 {
+			body := fmt.Sprintf("Some objects would have more than %v tags, no tags were changed.", api.MaxObjectTags)
+			openPrompt(prompt.NewMessage("", "Tags", body), cmds)
+			return
+		}
+
+		updates := model.tagsPreview.updates
+		model.tagsPreview = nil
+		*cmds = append(*cmds, runTagsUpdates(m, updates))
+
+	default:
+		var cmd tea.Cmd
+		model.tagsPreview.table, cmd = model.tagsPreview.table.Update(msg)
+		*cmds = append(*cmds, cmd)
+	}
+}
+
+// Objects that fail are listed at the end instead of stopping the rest
+func runTagsUpdates(m *types.UiModel, updates []tagsUpdate) tea.Cmd {
+	bucket := m.GetCurrentBucket()
+
+	return task.Run(tagsTask, func(report task.Reporter) (interface{}, error) {
+		result := tagsResult{}
+		n := int64(len(updates))
+		for i, u := range updates {
+			report(int64(i), n, fmt.Sprintf("Tagging %s", u.key))
+			if err := api.PutObjectTags(m.Session, bucket, u.key, u.tags); err != nil {
+				result.failed = append(result.failed, fmt.Sprintf("%s: %s", u.key, err))
+				continue
+			}
+			result.changed++
+		}
+
+		return result, nil
+	})
+}
+
+func handleTagsDone(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	model.progress = nil
+	model.table.ClearSelection()
+
+	r, _ := msg.Result.(tagsResult)
+	if msg.Err != nil {
+		openPrompt(prompt.NewMessage("", "Tagging failed", msg.Err.Error()), cmds)
+		return
+	}
+
+	if len(r.failed) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Tagged %v objects, %v could not be tagged:\n", r.changed, len(r.failed))
+		utils.WriteFailures(&b, r.failed)
+		openPrompt(prompt.NewMessage("", "Tagging finished with errors", b.String()), cmds)
+		return
+	}
+
+	openPrompt(prompt.NewMessage("", "Tags updated", fmt.Sprintf("Tagged %v objects.", r.changed)), cmds)
+}
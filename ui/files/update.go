@@ -2,15 +2,20 @@ package files
 
 import (
 	"fmt"
+	"s3-viewer/api"
 	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/components/location"
+	"s3-viewer/ui/components/prompt"
 	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/components/task"
 	"s3-viewer/ui/types"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/service/s3"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func handleGetFilesMsg(m *types.UiModel, msg getFilesMsg) {
+func handleGetFilesMsg(m *types.UiModel, msg getFilesMsg, cmds *[]tea.Cmd) {
 	if msg.err != nil {
 		panic(msg.err) //TODO do something actually meaningful here
 	}
@@ -19,7 +24,14 @@ func handleGetFilesMsg(m *types.UiModel, msg getFilesMsg) {
 	for i, p := range msg.objects.CommonPrefixes {
 		model.directories[i] = *p.Prefix
 	}
-	model.files = msg.objects.Contents
+	// The empty object marking the current folder is not shown as a file of itself
+	model.restoreStatus = make(map[string]*api.RestoreStatus)
+	model.files = make([]*s3.Object, 0, len(msg.objects.Contents))
+	for _, f := range msg.objects.Contents {
+		if *f.Key != m.GetCurrentPath() {
+			model.files = append(model.files, f)
+		}
+	}
 
 	if msg.objects.NextContinuationToken != nil {
 		model.continuationTokens = append(model.continuationTokens, msg.objects.NextContinuationToken)
@@ -30,7 +42,7 @@ func handleGetFilesMsg(m *types.UiModel, msg getFilesMsg) {
 
 	r := make([]table.Row, 0)
 	for _, d := range model.directories {
-		r = append(r, table.Row{icons.GetDirectoryIcon(), d, "", "", ""})
+		r = append(r, table.Row{icons.GetDirectoryIcon(), d, "", "", "", ""})
 	}
 	if model.files != nil {
 		for _, f := range model.files {
@@ -38,10 +50,25 @@ func handleGetFilesMsg(m *types.UiModel, msg getFilesMsg) {
 		}
 	}
 	model.table.SetData(r)
+
+	if model.focusKey != "" {
+		for i, row := range r {
+			if row[1] == model.focusKey {
+				model.table.SetHighlightedRow(i)
+			}
+		}
+		model.focusKey = ""
+	}
+
 	model.table.SetFooterInfo(fmt.Sprintf("%s/%s", m.GetCurrentBucket(), m.GetCurrentPath()))
+	checkRestoreStatus(m, cmds)
 }
 
 func handleFilterAppliedMsg(m *types.UiModel, msg table.FilterAppliedMsg, cmds *[]tea.Cmd) {
+	if model.archive != nil {
+		showArchiveRows(m, msg.Filter)
+		return
+	}
 	*cmds = append(*cmds, createGetFilesMsg(m, m.GetCurrentPath(), msg.Filter, nil))
 }
 
@@ -90,5 +117,126 @@ func handleEscKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
 
 func handleEnterKeyMsg(m *types.UiModel, msg tea.KeyMsg, cmds *[]tea.Cmd) {
 	r := model.table.GetHighlightedRow()
+	if r == nil {
+		return
+	}
+
+	if !isDirectoryRow(*r) {
+		if api.GetArchiveKind((*r)[1]) != "" {
+			handleOpenArchive(m, (*r)[1], cmds)
+			return
+		}
+		handlePreviewKeyMsg(m, cmds)
+		return
+	}
+
 	*cmds = append(*cmds, createGetFilesMsg(m, (*r)[1], "", nil))
 }
+
+func handlePreviewKeyMsg(m *types.UiModel, cmds *[]tea.Cmd) {
+	r := model.table.GetHighlightedRow()
+	if r == nil || isDirectoryRow(*r) {
+		return
+	}
+
+	*cmds = append(*cmds, m.SetCurrentObject((*r)[1]))
+}
+
+func handlePromptSubmittedMsg(m *types.UiModel, msg prompt.SubmittedMsg, cmds *[]tea.Cmd) {
+	switch msg.Id {
+	case deleteConfirmPrompt, deletePrefixPrompt:
+		handleDeleteConfirmed(m, msg, cmds)
+
+	case copyPrompt:
+		handleCopyConfirmed(m, msg, cmds)
+
+	case renamePrompt, bulkRenamePrompt:
+		handleRenameConfirmed(m, msg, cmds)
+
+	case newFolderPrompt:
+		handleNewFolderConfirmed(m, msg, cmds)
+
+	case newFilePrompt:
+		handleNewFileConfirmed(m, msg, cmds)
+
+	case sharePrompt:
+		handleShareConfirmed(m, msg, cmds)
+
+	case shareResultPrompt:
+		handleShareResultConfirmed(m, msg, cmds)
+
+	case location.PromptId:
+		handleCopyLocationConfirmed(m, msg, cmds)
+
+	case downloadMemberPrompt:
+		handleDownloadMemberConfirmed(m, msg, cmds)
+
+	case editConflictPrompt:
+		handleEditConflictConfirmed(m, msg, cmds)
+
+	case metadataPrompt, bulkMetadataPrompt:
+		handleMetadataConfirmed(m, msg, cmds)
+
+	case tagPrompt:
+		handleTagConfirmed(m, msg, cmds)
+
+	case bulkTagsPrompt:
+		handleBulkTagsConfirmed(m, msg, cmds)
+
+	case storageClassPrompt:
+		handleStorageClassConfirmed(m, msg, cmds)
+
+	case restorePrompt:
+		handleRestoreConfirmed(m, msg, cmds)
+
+	default:
+		closePrompt()
+	}
+}
+
+func handlePromptCancelledMsg(m *types.UiModel, msg prompt.CancelledMsg, cmds *[]tea.Cmd) {
+	model.pendingDelete = nil
+	model.pendingCopy = nil
+	model.pendingRename = nil
+	model.pendingMetadata = nil
+	model.pendingTags = nil
+	model.pendingStorage = nil
+	model.downloadMember = ""
+	closePrompt()
+
+	// Cancelling the conflict keeps the edit like abort does
+	if msg.Id == editConflictPrompt {
+		abortEdit(cmds)
+	}
+}
+
+func handleTaskDoneMsg(m *types.UiModel, msg task.DoneMsg, cmds *[]tea.Cmd) {
+	switch msg.Id {
+	case deleteTask:
+		handleDeleteDone(m, msg, cmds)
+
+	case copyTask:
+		handleCopyDone(m, msg, cmds)
+
+	case renameTask:
+		handleRenameDone(m, msg, cmds)
+
+	case metadataScanTask:
+		handleMetadataScanDone(m, msg, cmds)
+
+	case metadataTask:
+		handleMetadataDone(m, msg, cmds)
+
+	case tagsScanTask:
+		handleTagsScanDone(m, msg, cmds)
+
+	case tagsTask:
+		handleTagsDone(m, msg, cmds)
+
+	case storageClassTask:
+		handleStorageClassDone(m, msg, cmds)
+
+	case restoreTask:
+		handleRestoreDone(m, msg, cmds)
+	}
+}
@@ -0,0 +1,530 @@
+package preview
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Server access logs are delivered as TargetPrefix + YYYY-mm-DD-HH-MM-SS-UniqueString
+var accessLogName = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}-\d{2}-\d{2}-[0-9A-F]{16}$`)
+
+const accessLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// The first tab lists the requests, the others count them by one of their fields
+var accessLogTabs = []struct {
+	name  string
+	field string
+}{
+	{"Requests", ""},
+	{"Requesters", "requester"},
+	{"Keys", "key"},
+	{"Operations", "op"},
+	{"Statuses", "status"},
+	{"IPs", "ip"},
+}
+
+type accessLogRecord struct {
+	time       time.Time
+	bucket     string
+	remoteIP   string
+	requester  string
+	operation  string
+	key        string
+	requestURI string
+	status     string
+	errorCode  string
+	bytesSent  int64
+	totalTime  int64 // Milliseconds
+	userAgent  string
+	line       string
+}
+
+// Reads the S3 server access logs of one or more objects into a table of requests, with tabs counting
+// them by requester, key, operation, status and IP.  The filter applies to every tab.
+type accessLogViewer struct {
+	read       func(key string) ([]byte, error)
+	keys       []string
+	loaded     int // Objects read so far
+	failed     int
+	lastErr    error
+	records    []*accessLogRecord
+	skipped    int // Lines that are not access log records
+	truncated  bool
+	filterText string
+	matching   int
+	tab        int
+	tables     []*table.Model // One per tab, nil until every object is read
+	width      int
+	height     int
+}
+
+type accessLogReadMsg struct {
+	viewer *accessLogViewer
+	data   []byte
+	err    error
+}
+
+// Logs are recognized by the name S3 gives them or by their first line
+func isAccessLog(key string, head []byte) bool {
+	if accessLogName.MatchString(path.Base(key)) {
+		return true
+	}
+
+	line, _, _ := strings.Cut(string(head), "\n")
+	_, ok := parseAccessLogLine(line)
+	return ok
+}
+
+func newAccessLogViewer(m *types.UiModel, keys []string) (*accessLogViewer, tea.Cmd) {
+	v := &accessLogViewer{
+		read: func(key string) ([]byte, error) {
+			return readLogObject(m, key)
+		},
+		keys: keys,
+	}
+
+	return v, v.readNext()
+}
+
+func (v *accessLogViewer) readNext() tea.Cmd {
+	key := v.keys[v.loaded]
+	return func() tea.Msg {
+		b, err := v.read(key)
+		return accessLogReadMsg{v, b, err}
+	}
+}
+
+// Splits a log line into its fields.  The time is in [brackets], the request and user agent are quoted,
+// all of them contain spaces.
+func splitAccessLogLine(line string) []string {
+	fields := make([]string, 0, 26)
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+
+		case '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i+1:i+end])
+			i += end + 1
+
+		case '"':
+			// User agents may contain quotes, the field only ends at a quote followed by a space
+			j := i + 1
+			for j < len(line) && !(line[j] == '"' && (j+1 == len(line) || line[j+1] == ' ')) {
+				j++
+			}
+			fields = append(fields, line[i+1:j])
+			i = j + 1
+
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+
+	return fields
+}
+
+func parseAccessLogLine(line string) (*accessLogRecord, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	f := splitAccessLogLine(line)
+	// Older logs end with the user agent, newer ones add more fields after it
+	if len(f) < 17 {
+		return nil, false
+	}
+
+	t, err := time.Parse(accessLogTime, f[2])
+	if err != nil || !strings.Contains(f[6], ".") {
+		return nil, false
+	}
+	if _, err := strconv.Atoi(f[9]); err != nil && f[9] != "-" {
+		return nil, false
+	}
+
+	// Keys are URL encoded in the log
+	key := f[7]
+	if k, err := url.QueryUnescape(key); err == nil {
+		key = k
+	}
+
+	return &accessLogRecord{
+		time:       t,
+		bucket:     f[1],
+		remoteIP:   f[3],
+		requester:  f[4],
+		operation:  f[6],
+		key:        key,
+		requestURI: f[8],
+		status:     f[9],
+		errorCode:  f[10],
+		bytesSent:  parseLogNumber(f[11]),
+		totalTime:  parseLogNumber(f[13]),
+		userAgent:  f[16],
+		line:       line,
+	}, true
+}
+
+// - stands for a missing number
+func parseLogNumber(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func (r *accessLogRecord) field(name string) (string, bool) {
+	switch name {
+	case "requester":
+		return r.requester, true
+	case "op", "operation":
+		return r.operation, true
+	case "key":
+		return r.key, true
+	case "status":
+		return r.status, true
+	case "error":
+		return r.errorCode, true
+	case "ip":
+		return r.remoteIP, true
+	case "agent":
+		return r.userAgent, true
+	case "bucket":
+		return r.bucket, true
+	case "uri":
+		return r.requestURI, true
+	}
+
+	return "", false
+}
+
+func (r *accessLogRecord) isError() bool {
+	return r.status >= "400"
+}
+
+func (v *accessLogViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *accessLogViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case accessLogReadMsg:
+		if msg.viewer != v {
+			return nil
+		}
+		return v.handleRead(msg)
+
+	case table.FilterAppliedMsg:
+		if v.tables != nil {
+			v.filterText = msg.Filter
+			v.buildTables()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		if v.tables == nil {
+			return nil
+		}
+		t := v.tables[v.tab]
+		if t.IsFilterVisible() {
+			_, cmd := t.Update(msg)
+			return cmd
+		}
+
+		switch msg.String() {
+		case "tab":
+			v.tab = (v.tab + 1) % len(accessLogTabs)
+		case "shift+tab":
+			v.tab = (v.tab + len(accessLogTabs) - 1) % len(accessLogTabs)
+		case "1", "2", "3", "4", "5", "6":
+			v.tab = int(msg.String()[0] - '1')
+
+		case "enter":
+			v.handleEnterKey()
+
+		case "esc":
+			// The filter is cleared first, then the requests come back
+			if v.filterText == "" && v.tab != 0 {
+				v.tab = 0
+				return nil
+			}
+			_, cmd := t.Update(msg)
+			return cmd
+
+		default:
+			_, cmd := t.Update(msg)
+			return cmd
+		}
+
+	default:
+		// Cursor blink of the filter input
+		if v.tables != nil {
+			_, cmd := v.tables[v.tab].Update(msg)
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+func (v *accessLogViewer) handleRead(msg accessLogReadMsg) tea.Cmd {
+	v.loaded++
+	if msg.err != nil {
+		v.failed++
+		v.lastErr = msg.err
+	}
+
+	for _, l := range strings.Split(string(msg.data), "\n") {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if len(v.records) >= maxLogRecords {
+			v.truncated = true
+			break
+		}
+		if r, ok := parseAccessLogLine(l); ok {
+			v.records = append(v.records, r)
+		} else {
+			v.skipped++
+		}
+	}
+
+	if v.loaded < len(v.keys) && !v.truncated {
+		return v.readNext()
+	}
+
+	sort.SliceStable(v.records, func(i, j int) bool {
+		return v.records[i].time.Before(v.records[j].time)
+	})
+	v.buildTables()
+
+	return nil
+}
+
+// Narrows the requests down to the value of the highlighted row of a count
+func (v *accessLogViewer) handleEnterKey() {
+	field := accessLogTabs[v.tab].field
+	r := v.tables[v.tab].GetHighlightedRow()
+	if field == "" || r == nil {
+		return
+	}
+
+	term := fmt.Sprintf("%s=%s", field, quoteLogFilterValue((*r)[0]))
+	v.filterText = strings.TrimSpace(v.filterText + " " + term)
+	v.tab = 0
+	v.buildTables()
+}
+
+func (v *accessLogViewer) buildTables() {
+	terms := parseLogFilter(v.filterText)
+	matching := make([]*accessLogRecord, 0, len(v.records))
+	for _, r := range v.records {
+		ok := true
+		for _, t := range terms {
+			if !t.matches(r.field, r.line) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matching = append(matching, r)
+		}
+	}
+	v.matching = len(matching)
+
+	v.tables = make([]*table.Model, len(accessLogTabs))
+	v.tables[0] = newAccessLogTable(matching)
+	for i, tab := range accessLogTabs[1:] {
+		v.tables[i+1] = newAccessLogCountTable(matching, tab.name, tab.field)
+	}
+
+	for _, t := range v.tables {
+		t.SetFilter(v.filterText)
+	}
+}
+
+func newAccessLogTable(records []*accessLogRecord) *table.Model {
+	columns := []table.Column{
+		{Name: "Time", Width: 24, PlainText: true},
+		{Name: "Requester", Width: 40, PlainText: true},
+		{Name: "Operation", Width: 30, PlainText: true},
+		{Name: "Key", Width: 50, PlainText: true},
+		{Name: "Status", Width: 11, PlainText: true},
+		{Name: "Error", Width: 25, PlainText: true},
+		{Name: "Bytes", Width: 15, PlainText: true},
+		{Name: "Latency", Width: 14, PlainText: true},
+		{Name: "IP", Width: 20, PlainText: true},
+		{Name: "User agent", Width: 60, PlainText: true},
+	}
+
+	rows := make([]table.Row, len(records))
+	for i, r := range records {
+		rows[i] = table.Row{
+			r.time.Format(time.DateTime),
+			sanitizeLine(r.requester),
+			r.operation,
+			sanitizeLine(r.key),
+			r.status,
+			r.errorCode,
+			utils.GetFriendlyByteDisplay(r.bytesSent),
+			fmt.Sprintf("%v ms", r.totalTime),
+			r.remoteIP,
+			sanitizeLine(r.userAgent),
+		}
+	}
+
+	t := table.New(columns, true)
+	t.EnableHorizontalScroll()
+	t.SetData(rows)
+
+	return t
+}
+
+type accessLogCount struct {
+	value     string
+	requests  int
+	errors    int
+	bytesSent int64
+	totalTime int64
+}
+
+// Counts the records by the value of field, the busiest first
+func newAccessLogCountTable(records []*accessLogRecord, name, field string) *table.Model {
+	counts := make(map[string]*accessLogCount)
+	for _, r := range records {
+		value, _ := r.field(field)
+		c, ok := counts[value]
+		if !ok {
+			c = &accessLogCount{value: value}
+			counts[value] = c
+		}
+		c.requests++
+		if r.isError() {
+			c.errors++
+		}
+		c.bytesSent += r.bytesSent
+		c.totalTime += r.totalTime
+	}
+
+	sorted := make([]*accessLogCount, 0, len(counts))
+	for _, c := range counts {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].requests != sorted[j].requests {
+			return sorted[i].requests > sorted[j].requests
+		}
+		return sorted[i].value < sorted[j].value
+	})
+
+	columns := []table.Column{
+		{Name: strings.TrimSuffix(name, "s"), Width: 70, PlainText: true},
+		{Name: "Requests", Width: 14, PlainText: true},
+		{Name: "Errors", Width: 12, PlainText: true},
+		{Name: "Bytes sent", Width: 16, PlainText: true},
+		{Name: "Avg latency", Width: 16, PlainText: true},
+	}
+
+	rows := make([]table.Row, len(sorted))
+	for i, c := range sorted {
+		rows[i] = table.Row{
+			sanitizeLine(c.value),
+			strconv.Itoa(c.requests),
+			strconv.Itoa(c.errors),
+			utils.GetFriendlyByteDisplay(c.bytesSent),
+			fmt.Sprintf("%v ms", c.totalTime/int64(c.requests)),
+		}
+	}
+
+	t := table.New(columns, true)
+	t.EnableHorizontalScroll()
+	t.SetData(rows)
+	t.SetFooterInfo(fmt.Sprintf("%v %s", len(rows), strings.ToLower(name)))
+
+	return t
+}
+
+func (v *accessLogViewer) view() string {
+	if v.tables == nil {
+		return lineNumberStyle.Render(fmt.Sprintf("Reading %v/%v logs", v.loaded, len(v.keys)))
+	}
+	if len(v.records) == 0 {
+		if v.lastErr != nil {
+			return errorStyle.Render(v.lastErr.Error())
+		}
+		return lineNumberStyle.Render("No access log records found")
+	}
+
+	tabs := make([]string, len(accessLogTabs))
+	for i, t := range accessLogTabs {
+		style := tabStyle
+		if i == v.tab {
+			style = activeTabStyle
+		}
+		tabs[i] = style.Render(fmt.Sprintf("%v %s", i+1, t.name))
+	}
+	bar := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Top, lipgloss.JoinVertical(lipgloss.Center, bar, v.tables[v.tab].View()))
+}
+
+func (v *accessLogViewer) status() string {
+	if v.tables == nil {
+		return "access log"
+	}
+
+	s := fmt.Sprintf("%v requests", len(v.records))
+	if v.filterText != "" {
+		s = fmt.Sprintf("%v of %v requests", v.matching, len(v.records))
+	}
+	if len(v.keys) > 1 {
+		s = fmt.Sprintf("%s • %v logs", s, v.loaded)
+	}
+	if v.truncated {
+		s = fmt.Sprintf("first %v records • %s", maxLogRecords, s)
+	}
+	if v.skipped > 0 {
+		s = fmt.Sprintf("%s • %v lines skipped", s, v.skipped)
+	}
+	if v.failed > 0 {
+		s = fmt.Sprintf("%v logs could not be read • %s", v.failed, s)
+	}
+
+	return s
+}
+
+func (v *accessLogViewer) help() string {
+	if v.tables == nil {
+		return help.GetLogPreviewHelp(false, "", false)
+	}
+
+	t := v.tables[v.tab]
+	return help.GetLogPreviewHelp(t.IsFilterVisible(), v.filterText, v.tab != 0)
+}
+
+func (v *accessLogViewer) isCapturingKeys() bool {
+	if v.tables == nil {
+		return false
+	}
+
+	return v.tables[v.tab].IsFilterVisible() || v.filterText != "" || v.tab != 0
+}
@@ -0,0 +1,79 @@
+package preview
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The example from the S3 documentation
+const sampleAccessLogLine = `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be 3E57427F3EXAMPLE REST.GET.VERSIONING my%20photo.jpg "GET /awsexamplebucket1?versioning HTTP/1.1" 200 - 113 - 7 - "-" "S3Console/0.4" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSV1.2 - -`
+
+func TestSplitAccessLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"plain", "a b  c", []string{"a", "b", "c"}},
+		{"brackets", "a [06/Feb/2019:00:00:38 +0000] b", []string{"a", "06/Feb/2019:00:00:38 +0000", "b"}},
+		{"quotes", `a "GET / HTTP/1.1" b`, []string{"a", "GET / HTTP/1.1", "b"}},
+		{"quote inside", `a "agent (x"y)" b`, []string{"a", `agent (x"y)`, "b"}},
+		{"quote at the end", `a "S3Console/0.4"`, []string{"a", "S3Console/0.4"}},
+		{"unclosed bracket", "a [06/Feb", []string{"a", "06/Feb"}},
+		{"unclosed quote", `a "GET /`, []string{"a", "GET /"}},
+		{"empty", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitAccessLogLine(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAccessLogLine(t *testing.T) {
+	r, ok := parseAccessLogLine(sampleAccessLogLine + "\r")
+	if !ok {
+		t.Fatal("the sample line was not parsed")
+	}
+
+	want := &accessLogRecord{
+		time:       time.Date(2019, 2, 6, 0, 0, 38, 0, time.FixedZone("", 0)),
+		bucket:     "awsexamplebucket1",
+		remoteIP:   "192.0.2.3",
+		requester:  "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+		operation:  "REST.GET.VERSIONING",
+		key:        "my photo.jpg",
+		requestURI: "GET /awsexamplebucket1?versioning HTTP/1.1",
+		status:     "200",
+		errorCode:  "-",
+		bytesSent:  113,
+		totalTime:  7,
+		userAgent:  "S3Console/0.4",
+		line:       sampleAccessLogLine,
+	}
+	if !r.time.Equal(want.time) {
+		t.Errorf("time = %v, want %v", r.time, want.time)
+	}
+	r.time = want.time
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("got %+v, want %+v", r, want)
+	}
+
+	invalid := []string{
+		"",
+		"just some text",
+		"a b [not a time] c d e REST.GET.OBJECT k \"GET / HTTP/1.1\" 200 - 1 - 1 - \"-\" \"agent\"",
+		"a b [06/Feb/2019:00:00:38 +0000] c d e GETOBJECT k \"GET / HTTP/1.1\" 200 - 1 - 1 - \"-\" \"agent\"",
+		"a b [06/Feb/2019:00:00:38 +0000] c d e REST.GET.OBJECT k \"GET / HTTP/1.1\" OK - 1 - 1 - \"-\" \"agent\"",
+		"a b [06/Feb/2019:00:00:38 +0000] c d e REST.GET.OBJECT k \"GET / HTTP/1.1\" 200 - 1 - 1",
+	}
+	for _, l := range invalid {
+		if _, ok := parseAccessLogLine(l); ok {
+			t.Errorf("parseAccessLogLine(%q) was accepted", l)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package preview
+
+// Pixel size of a terminal cell, other systems do not report it
+func getCellSize() (int, int) {
+	return defaultCellWidth, defaultCellHeight
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package preview
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Pixel size of a terminal cell, needed to fit images drawn by the terminal
+func getCellSize() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 || ws.Xpixel == 0 || ws.Ypixel == 0 {
+		return defaultCellWidth, defaultCellHeight
+	}
+
+	return int(ws.Xpixel) / int(ws.Col), int(ws.Ypixel) / int(ws.Row)
+}
@@ -0,0 +1,375 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/types"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Trails deliver to AWSLogs/<account>/CloudTrail/<region>/yyyy/mm/dd/, organization trails put the
+// organization id before the account
+var cloudTrailPrefix = regexp.MustCompile(`(^|/)AWSLogs/(o-[a-z0-9]+/)?\d{12}/CloudTrail/[a-z0-9-]+/\d{4}/\d{2}/\d{2}/`)
+
+type cloudTrailRecord struct {
+	time      time.Time
+	event     string
+	source    string
+	identity  string
+	sourceIP  string
+	errorCode string
+	region    string
+	userAgent string
+	raw       json.RawMessage
+}
+
+// The parts of a CloudTrail event shown in the table, the rest is only in the detail
+type cloudTrailEvent struct {
+	EventTime       time.Time `json:"eventTime"`
+	EventName       string    `json:"eventName"`
+	EventSource     string    `json:"eventSource"`
+	AwsRegion       string    `json:"awsRegion"`
+	SourceIPAddress string    `json:"sourceIPAddress"`
+	UserAgent       string    `json:"userAgent"`
+	ErrorCode       string    `json:"errorCode"`
+	UserIdentity    struct {
+		Type        string `json:"type"`
+		PrincipalId string `json:"principalId"`
+		Arn         string `json:"arn"`
+		UserName    string `json:"userName"`
+		InvokedBy   string `json:"invokedBy"`
+	} `json:"userIdentity"`
+}
+
+// Flattens the records of the CloudTrail files of one or more objects, usually a whole day, into a
+// table.  Enter shows the whole record.
+type cloudTrailViewer struct {
+	read       func(key string) ([]byte, error)
+	keys       []string
+	loaded     int // Objects read so far
+	failed     int
+	lastErr    error
+	records    []*cloudTrailRecord
+	matching   []*cloudTrailRecord
+	truncated  bool
+	filterText string
+	table      *table.Model // Nil until every object is read
+	detail     *treeViewer  // The record opened with enter
+	width      int
+	height     int
+}
+
+type cloudTrailReadMsg struct {
+	viewer *cloudTrailViewer
+	data   []byte
+	err    error
+}
+
+func isCloudTrail(key string) bool {
+	return cloudTrailPrefix.MatchString(key)
+}
+
+func newCloudTrailViewer(m *types.UiModel, keys []string) (*cloudTrailViewer, tea.Cmd) {
+	v := &cloudTrailViewer{
+		read: func(key string) ([]byte, error) {
+			return readLogObject(m, key)
+		},
+		keys: keys,
+	}
+
+	return v, v.readNext()
+}
+
+func (v *cloudTrailViewer) readNext() tea.Cmd {
+	key := v.keys[v.loaded]
+	return func() tea.Msg {
+		b, err := v.read(key)
+		return cloudTrailReadMsg{v, b, err}
+	}
+}
+
+func parseCloudTrailFile(data []byte) ([]*cloudTrailRecord, error) {
+	var file struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	records := make([]*cloudTrailRecord, 0, len(file.Records))
+	for _, raw := range file.Records {
+		var e cloudTrailEvent
+		if err := json.Unmarshal(raw, &e); err != nil {
+			continue
+		}
+
+		records = append(records, &cloudTrailRecord{
+			time:      e.EventTime,
+			event:     e.EventName,
+			source:    strings.TrimSuffix(e.EventSource, ".amazonaws.com"),
+			identity:  getCloudTrailIdentity(e),
+			sourceIP:  e.SourceIPAddress,
+			errorCode: e.ErrorCode,
+			region:    e.AwsRegion,
+			userAgent: e.UserAgent,
+			raw:       raw,
+		})
+	}
+
+	return records, nil
+}
+
+// The most readable name of whoever made the call, services calling on behalf of someone only have invokedBy
+func getCloudTrailIdentity(e cloudTrailEvent) string {
+	id := e.UserIdentity
+	switch {
+	case id.Arn != "":
+		return id.Arn
+	case id.UserName != "":
+		return id.UserName
+	case id.InvokedBy != "":
+		return id.InvokedBy
+	case id.PrincipalId != "":
+		return id.PrincipalId
+	}
+
+	return id.Type
+}
+
+func (r *cloudTrailRecord) field(name string) (string, bool) {
+	switch name {
+	case "event":
+		return r.event, true
+	case "source":
+		return r.source, true
+	case "user":
+		return r.identity, true
+	case "ip":
+		return r.sourceIP, true
+	case "error":
+		return r.errorCode, true
+	case "region":
+		return r.region, true
+	case "agent":
+		return r.userAgent, true
+	}
+
+	return "", false
+}
+
+func (v *cloudTrailViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+	if v.detail != nil {
+		v.detail.setSize(width, height)
+	}
+}
+
+func (v *cloudTrailViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case cloudTrailReadMsg:
+		if msg.viewer != v {
+			return nil
+		}
+		return v.handleRead(msg)
+
+	case table.FilterAppliedMsg:
+		if v.table != nil {
+			v.filterText = msg.Filter
+			v.buildTable()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		if v.detail != nil {
+			if msg.String() == "esc" && !v.detail.isCapturingKeys() {
+				v.detail = nil
+				return nil
+			}
+			return v.detail.update(m, msg)
+		}
+		if v.table == nil {
+			return nil
+		}
+
+		if msg.String() == "enter" && !v.table.IsFilterVisible() {
+			return v.openDetail()
+		}
+		_, cmd := v.table.Update(msg)
+		return cmd
+	}
+
+	if v.detail != nil {
+		return v.detail.update(m, msg)
+	}
+	// Cursor blink of the filter input
+	if v.table != nil {
+		_, cmd := v.table.Update(msg)
+		return cmd
+	}
+
+	return nil
+}
+
+func (v *cloudTrailViewer) handleRead(msg cloudTrailReadMsg) tea.Cmd {
+	v.loaded++
+	var records []*cloudTrailRecord
+	err := msg.err
+	if err == nil {
+		records, err = parseCloudTrailFile(msg.data)
+	}
+	if err != nil {
+		v.failed++
+		v.lastErr = fmt.Errorf("%s: %w", v.keys[v.loaded-1], err)
+	}
+
+	if len(v.records)+len(records) > maxLogRecords {
+		records = records[:maxLogRecords-len(v.records)]
+		v.truncated = true
+	}
+	v.records = append(v.records, records...)
+
+	if v.loaded < len(v.keys) && !v.truncated {
+		return v.readNext()
+	}
+
+	sort.SliceStable(v.records, func(i, j int) bool {
+		return v.records[i].time.Before(v.records[j].time)
+	})
+	v.buildTable()
+
+	return nil
+}
+
+func (v *cloudTrailViewer) buildTable() {
+	terms := parseLogFilter(v.filterText)
+	v.matching = make([]*cloudTrailRecord, 0, len(v.records))
+	for _, r := range v.records {
+		ok := true
+		for _, t := range terms {
+			if !t.matches(r.field, string(r.raw)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			v.matching = append(v.matching, r)
+		}
+	}
+
+	columns := []table.Column{
+		{Name: "Time", Width: 24, PlainText: true},
+		{Name: "Event", Width: 35, PlainText: true},
+		{Name: "Source", Width: 25, PlainText: true},
+		{Name: "User identity", Width: 60, PlainText: true},
+		{Name: "Source IP", Width: 25, PlainText: true},
+		{Name: "Error code", Width: 30, PlainText: true},
+		{Name: "Region", Width: 20, PlainText: true},
+	}
+
+	rows := make([]table.Row, len(v.matching))
+	for i, r := range v.matching {
+		rows[i] = table.Row{
+			r.time.Format(time.DateTime),
+			r.event,
+			r.source,
+			sanitizeLine(r.identity),
+			r.sourceIP,
+			r.errorCode,
+			r.region,
+		}
+	}
+
+	v.table = table.New(columns, true)
+	v.table.EnableHorizontalScroll()
+	v.table.SetData(rows)
+	v.table.SetFilter(v.filterText)
+}
+
+// Shows the whole record in the tree viewer
+func (v *cloudTrailViewer) openDetail() tea.Cmd {
+	if len(v.matching) == 0 {
+		return nil
+	}
+
+	r := v.matching[v.table.GetHighlightedRowIndex()]
+	var cmd tea.Cmd
+	v.detail, cmd = newTreeViewer(&memorySource{data: r.raw}, "record.json", &api.ObjectInfo{Size: int64(len(r.raw))}, jsonDocument)
+	v.detail.setSize(v.width, v.height)
+
+	return cmd
+}
+
+func (v *cloudTrailViewer) view() string {
+	if v.detail != nil {
+		return v.detail.view()
+	}
+	if v.table == nil {
+		return lineNumberStyle.Render(fmt.Sprintf("Reading %v/%v CloudTrail files", v.loaded, len(v.keys)))
+	}
+	if len(v.records) == 0 {
+		if v.lastErr != nil {
+			return errorStyle.Render(v.lastErr.Error())
+		}
+		return lineNumberStyle.Render("No CloudTrail records found")
+	}
+
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Top, v.table.View())
+}
+
+func (v *cloudTrailViewer) status() string {
+	if v.detail != nil {
+		r := v.matching[v.table.GetHighlightedRowIndex()]
+		return fmt.Sprintf("%s • %s", r.event, v.detail.status())
+	}
+	if v.table == nil {
+		return "cloudtrail"
+	}
+
+	s := fmt.Sprintf("%v events", len(v.records))
+	if v.filterText != "" {
+		s = fmt.Sprintf("%v of %v events", len(v.matching), len(v.records))
+	}
+	if len(v.keys) > 1 {
+		s = fmt.Sprintf("%s • %v files", s, v.loaded)
+	}
+	if v.truncated {
+		s = fmt.Sprintf("first %v records • %s", maxLogRecords, s)
+	}
+	if v.failed > 0 {
+		s = fmt.Sprintf("%v files could not be read • %s", v.failed, s)
+	}
+
+	return s
+}
+
+func (v *cloudTrailViewer) help() string {
+	if v.detail != nil {
+		return v.detail.help()
+	}
+	if v.table == nil {
+		return help.GetCloudTrailPreviewHelp(false, "")
+	}
+
+	return help.GetCloudTrailPreviewHelp(v.table.IsFilterVisible(), v.filterText)
+}
+
+func (v *cloudTrailViewer) isCapturingKeys() bool {
+	if v.detail != nil {
+		return true
+	}
+	if v.table == nil {
+		return false
+	}
+
+	return v.table.IsFilterVisible() || v.filterText != ""
+}
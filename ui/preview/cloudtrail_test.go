@@ -0,0 +1,54 @@
+package preview
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCloudTrailFile(t *testing.T) {
+	data := []byte(`{"Records": [
+		{"eventTime": "2023-05-01T10:00:00Z", "eventName": "GetObject", "eventSource": "s3.amazonaws.com",
+		 "awsRegion": "eu-west-1", "sourceIPAddress": "192.0.2.1", "userAgent": "aws-cli",
+		 "userIdentity": {"type": "IAMUser", "arn": "arn:aws:iam::123456789012:user/alice", "userName": "alice"}},
+		{"eventTime": "2023-05-01T10:01:00Z", "eventName": "AssumeRole", "eventSource": "sts.amazonaws.com",
+		 "errorCode": "AccessDenied", "userIdentity": {"type": "AWSService", "invokedBy": "ec2.amazonaws.com"}},
+		{"eventTime": "not a time", "eventName": "Broken"},
+		{"eventTime": "2023-05-01T10:02:00Z", "eventName": "ConsoleLogin", "userIdentity": {"type": "Root"}}
+	]}`)
+
+	records, err := parseCloudTrailFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		time      time.Time
+		event     string
+		source    string
+		identity  string
+		errorCode string
+	}{
+		{time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC), "GetObject", "s3", "arn:aws:iam::123456789012:user/alice", ""},
+		{time.Date(2023, 5, 1, 10, 1, 0, 0, time.UTC), "AssumeRole", "sts", "ec2.amazonaws.com", "AccessDenied"},
+		{time.Date(2023, 5, 1, 10, 2, 0, 0, time.UTC), "ConsoleLogin", "", "Root", ""},
+	}
+	if len(records) != len(tests) {
+		t.Fatalf("got %v records, want %v", len(records), len(tests))
+	}
+	for i, tt := range tests {
+		r := records[i]
+		if !r.time.Equal(tt.time) || r.event != tt.event || r.source != tt.source || r.identity != tt.identity || r.errorCode != tt.errorCode {
+			t.Errorf("record %v = %+v", i, r)
+		}
+	}
+	if records[0].region != "eu-west-1" || records[0].sourceIP != "192.0.2.1" || records[0].userAgent != "aws-cli" {
+		t.Errorf("record 0 = %+v", records[0])
+	}
+
+	if records, err := parseCloudTrailFile([]byte(`{}`)); err != nil || len(records) != 0 {
+		t.Errorf("an empty file gave %v, %v", records, err)
+	}
+	if _, err := parseCloudTrailFile([]byte(`not json`)); err == nil {
+		t.Error("invalid json was accepted")
+	}
+}
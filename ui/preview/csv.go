@@ -0,0 +1,463 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/types"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// Only the start of huge exports is shown
+	maxCsvRows = 10000
+
+	minCsvColumnWidth = 8
+	maxCsvColumnWidth = 40
+)
+
+// Candidates for the delimiter, the first one wins a tie
+var csvDelimiters = []byte{',', '\t', ';', '|'}
+
+// Shows the first rows of a CSV or TSV object in the table component.  The first record is used as the
+// header, more rows are streamed in as the cursor gets close to the end.
+type csvViewer struct {
+	src       source
+	size      int64
+	isTsv     bool
+	data      []byte // Loaded bytes not parsed yet, the start of an incomplete record
+	isLoading bool
+	eof       bool
+	err       error
+	delimiter byte
+	quote     byte
+	header    []string
+	rowCount  int
+	table     *table.Model
+	width     int
+	height    int
+	jumpInput textinput.Model
+	isJumping bool
+	jumpTo    int // Row asked for before it was loaded, -1 when there is none
+	message   string
+}
+
+type csvChunkMsg struct {
+	src  source
+	data []byte
+	err  error
+}
+
+func isCsv(key, contentType string) bool {
+	switch strings.ToLower(icons.GetExtension(key)) {
+	case "csv", "tsv":
+		return true
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "text/csv" || mt == "text/tab-separated-values"
+}
+
+func newCsvViewer(src source, key string, info *api.ObjectInfo) (*csvViewer, tea.Cmd) {
+	ji := textinput.New()
+	ji.Prompt = "row: "
+	ji.Placeholder = "number"
+	ji.CharLimit = 12
+
+	v := &csvViewer{
+		src:       src,
+		size:      info.Size,
+		isTsv:     strings.EqualFold(icons.GetExtension(key), "tsv"),
+		jumpInput: ji,
+		jumpTo:    -1,
+	}
+
+	return v, v.loadMore()
+}
+
+func (v *csvViewer) loadMore() tea.Cmd {
+	if v.isLoading || v.eof || v.err != nil || v.rowCount >= maxCsvRows {
+		return nil
+	}
+
+	v.isLoading = true
+	src := v.src
+	return func() tea.Msg {
+		b, err := src.Next()
+		return csvChunkMsg{src, b, err}
+	}
+}
+
+func (v *csvViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *csvViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msg := msg.(type) {
+	case csvChunkMsg:
+		if msg.src != v.src {
+			return nil
+		}
+		v.handleChunk(msg)
+
+	case tea.KeyMsg:
+		v.message = ""
+		if v.isJumping {
+			return v.handleJumpKey(msg)
+		}
+		if v.table == nil {
+			return nil
+		}
+
+		switch msg.String() {
+		case ":":
+			v.isJumping = true
+			v.jumpInput.SetValue("")
+			v.jumpInput.Focus()
+			return textinput.Blink
+
+		case "g", "home":
+			v.table.SetHighlightedRow(0)
+
+		case "G", "end":
+			v.table.SetHighlightedRow(v.rowCount - 1)
+
+		default:
+			var cmd tea.Cmd
+			v.table, cmd = v.table.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	default:
+		if v.isJumping {
+			var cmd tea.Cmd
+			v.jumpInput, cmd = v.jumpInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		if v.table != nil {
+			var cmd tea.Cmd
+			v.table, cmd = v.table.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	// Keep a screen of rows ahead of the cursor, or keep going until the row to jump to is there.  A
+	// header longer than a chunk needs more chunks before there is a table.
+	if v.header == nil || v.jumpTo >= 0 || v.rowCount-v.table.GetHighlightedRowIndex() < v.height {
+		cmds = append(cmds, v.loadMore())
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (v *csvViewer) handleChunk(msg csvChunkMsg) {
+	v.isLoading = false
+	if errors.Is(msg.err, io.EOF) {
+		v.eof = true
+	} else if msg.err != nil {
+		v.err = msg.err
+		return
+	} else {
+		v.data = append(v.data, msg.data...)
+		if v.src.Done() {
+			v.eof = true
+		}
+	}
+
+	// The dialect is guessed once the first line is complete
+	if v.delimiter == 0 {
+		if !v.eof && bytes.IndexByte(v.data, '\n') < 0 {
+			return
+		}
+		v.delimiter, v.quote = detectCsvDialect(v.data, v.isTsv)
+	}
+
+	records, consumed := parseCsv(v.data, v.delimiter, v.quote, v.eof)
+	v.data = v.data[consumed:]
+	if len(records) == 0 {
+		return
+	}
+
+	if v.header == nil {
+		v.header = records[0]
+		records = records[1:]
+		v.table = newCsvTable(v.header)
+		v.table.SetData(make([]table.Row, 0))
+	}
+
+	if v.rowCount+len(records) > maxCsvRows {
+		records = records[:maxCsvRows-v.rowCount]
+	}
+
+	rows := make([]table.Row, len(records))
+	for i, r := range records {
+		// Ragged records are padded or cut to the header
+		row := make(table.Row, len(v.header))
+		for j := range row {
+			if j < len(r) {
+				row[j] = sanitizeLine(r[j])
+			}
+		}
+		rows[i] = row
+	}
+	v.table.AppendData(rows)
+	v.rowCount += len(rows)
+	v.table.SetFooterInfo(v.getFooterInfo())
+
+	if v.jumpTo >= 0 && (v.jumpTo < v.rowCount || v.eof || v.rowCount >= maxCsvRows) {
+		v.jump(v.jumpTo)
+	}
+}
+
+func newCsvTable(header []string) *table.Model {
+	columns := make([]table.Column, len(header))
+	for i, h := range header {
+		// The table cuts values 5 characters before the column width
+		w := lipgloss.Width(h) + 5
+		if w < minCsvColumnWidth {
+			w = minCsvColumnWidth
+		}
+		if w > maxCsvColumnWidth {
+			w = maxCsvColumnWidth
+		}
+		columns[i] = table.Column{Name: sanitizeLine(h), Width: w, PlainText: true}
+	}
+
+	t := table.New(columns, false)
+	t.EnableHorizontalScroll()
+
+	return t
+}
+
+func (v *csvViewer) handleJumpKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		v.isJumping = false
+		v.jumpInput.Blur()
+		return nil
+
+	case "enter":
+		v.isJumping = false
+		v.jumpInput.Blur()
+
+		n, err := strconv.Atoi(strings.TrimSpace(v.jumpInput.Value()))
+		if err != nil || n < 1 {
+			v.message = "not a row number"
+			return nil
+		}
+		if n > maxCsvRows {
+			v.message = fmt.Sprintf("only the first %v rows are shown", maxCsvRows)
+			n = maxCsvRows
+		}
+
+		if n-1 < v.rowCount || v.eof {
+			v.jump(n - 1)
+			return nil
+		}
+		v.jumpTo = n - 1
+		return v.loadMore()
+	}
+
+	var cmd tea.Cmd
+	v.jumpInput, cmd = v.jumpInput.Update(msg)
+	return cmd
+}
+
+func (v *csvViewer) jump(i int) {
+	v.jumpTo = -1
+	if i >= v.rowCount {
+		i = v.rowCount - 1
+		v.message = fmt.Sprintf("there are only %v rows", v.rowCount)
+	}
+	v.table.SetHighlightedRow(i)
+}
+
+func (v *csvViewer) getFooterInfo() string {
+	d := string(v.delimiter)
+	if v.delimiter == '\t' {
+		d = "tab"
+	}
+
+	return fmt.Sprintf("delimiter %s", d)
+}
+
+// Guesses the delimiter from the first lines, it is the candidate found the same number of times on
+// most of them.  The quote is ' when more fields start with it than with ".
+func detectCsvDialect(sample []byte, isTsv bool) (byte, byte) {
+	lines := make([][]byte, 0)
+	all := bytes.Split(sample, []byte("\n"))
+	// The last line is probably cut off
+	if len(all) > 1 {
+		all = all[:len(all)-1]
+	}
+	for _, l := range all {
+		if len(bytes.TrimSpace(l)) > 0 && len(lines) < 10 {
+			lines = append(lines, l)
+		}
+	}
+
+	quote := byte('"')
+	double, single := 0, 0
+	for _, l := range lines {
+		for _, f := range bytes.FieldsFunc(l, isCsvDelimiter) {
+			f = bytes.TrimSpace(f)
+			if len(f) > 0 && f[0] == '"' {
+				double++
+			} else if len(f) > 0 && f[0] == '\'' {
+				single++
+			}
+		}
+	}
+	if single > double {
+		quote = '\''
+	}
+
+	if isTsv {
+		return '\t', quote
+	}
+
+	best, bestLines := byte(','), 0
+	for _, d := range csvDelimiters {
+		// How many lines have the delimiter the same number of times
+		frequency := make(map[int]int)
+		for _, l := range lines {
+			if c := countOutsideQuotes(l, d, quote); c > 0 {
+				frequency[c]++
+			}
+		}
+		for _, n := range frequency {
+			if n > bestLines {
+				best, bestLines = d, n
+			}
+		}
+	}
+
+	return best, quote
+}
+
+func isCsvDelimiter(r rune) bool {
+	return bytes.ContainsRune(csvDelimiters, r)
+}
+
+func countOutsideQuotes(line []byte, c, quote byte) int {
+	count := 0
+	quoted := false
+	for _, b := range line {
+		if b == quote {
+			quoted = !quoted
+		} else if b == c && !quoted {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Splits data into records.  A quoted field may contain delimiters, new lines and doubled quotes.  When
+// final is false the last record may be incomplete so it is left for later, consumed is where it starts.
+func parseCsv(data []byte, delimiter, quote byte, final bool) ([][]string, int) {
+	records := make([][]string, 0)
+	record := make([]string, 0)
+	var field bytes.Buffer
+	quoted := false
+	start := 0
+
+	endRecord := func(next int) {
+		record = append(record, strings.TrimSuffix(field.String(), "\r"))
+		field.Reset()
+		// Skip blank lines
+		if len(record) > 1 || record[0] != "" {
+			records = append(records, record)
+		}
+		record = make([]string, 0)
+		start = next
+	}
+
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case quoted && b == quote:
+			if i+1 < len(data) && data[i+1] == quote {
+				field.WriteByte(quote)
+				i++
+			} else {
+				quoted = false
+			}
+		case quoted:
+			field.WriteByte(b)
+		case b == quote && field.Len() == 0:
+			quoted = true
+		case b == delimiter:
+			record = append(record, field.String())
+			field.Reset()
+		case b == '\n':
+			endRecord(i + 1)
+		default:
+			field.WriteByte(b)
+		}
+	}
+
+	if final && (field.Len() > 0 || len(record) > 0) {
+		endRecord(len(data))
+	}
+
+	return records, start
+}
+
+func (v *csvViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.table == nil {
+		if v.eof {
+			return lineNumberStyle.Render("(empty)")
+		}
+		return lineNumberStyle.Render("Loading")
+	}
+
+	t := v.table.View()
+	if v.isJumping {
+		t = lipgloss.JoinVertical(lipgloss.Left, t, searchStyle.Render(v.jumpInput.View()))
+	}
+
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Top, t)
+}
+
+func (v *csvViewer) status() string {
+	s := fmt.Sprintf("%v rows", v.rowCount)
+	if v.rowCount >= maxCsvRows {
+		s = fmt.Sprintf("first %v rows", maxCsvRows)
+	} else if !v.eof {
+		s = fmt.Sprintf("%v+ rows", v.rowCount)
+	}
+	if v.header != nil {
+		s = fmt.Sprintf("%s • %v columns", s, len(v.header))
+	}
+	if v.message != "" {
+		s = fmt.Sprintf("%s • %s", v.message, s)
+	}
+
+	return s
+}
+
+func (v *csvViewer) help() string {
+	return help.GetCsvPreviewHelp(v.isJumping)
+}
+
+func (v *csvViewer) isCapturingKeys() bool {
+	return v.isJumping
+}
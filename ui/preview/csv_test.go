@@ -0,0 +1,91 @@
+package preview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectCsvDialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		sample    string
+		isTsv     bool
+		delimiter byte
+		quote     byte
+	}{
+		{"comma", "a,b,c\n1,2,3\n4,5,6\n", false, ',', '"'},
+		{"semicolon", "a;b;c\n1,5;2;3\n4;5,5;6\n", false, ';', '"'},
+		{"tab", "a\tb\n1\t2\n", false, '\t', '"'},
+		{"pipe", "a|b|c\n1|2|3\n", false, '|', '"'},
+		{"tsv extension", "a,b\tc\n1,2\t3\n", true, '\t', '"'},
+		{"single quotes", "'a','b'\n'1','2'\n", false, ',', '\''},
+		{"quoted delimiters", "\"a;b\",c\n\"1;2\",3\n", false, ',', '"'},
+		{"cut off last line", "a,b,c\n1,2,3\n4;5;6;7;8", false, ',', '"'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, q := detectCsvDialect([]byte(tt.sample), tt.isTsv)
+			if d != tt.delimiter || q != tt.quote {
+				t.Errorf("got %q %q, want %q %q", d, q, tt.delimiter, tt.quote)
+			}
+		})
+	}
+}
+
+func TestParseCsv(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		final    bool
+		records  [][]string
+		consumed int
+	}{
+		{
+			name:     "simple",
+			data:     "a,b\n1,2\n",
+			records:  [][]string{{"a", "b"}, {"1", "2"}},
+			consumed: 8,
+		},
+		{
+			name:     "incomplete last record is left",
+			data:     "a,b\n1,2",
+			records:  [][]string{{"a", "b"}},
+			consumed: 4,
+		},
+		{
+			name:     "incomplete last record when final",
+			data:     "a,b\n1,2",
+			final:    true,
+			records:  [][]string{{"a", "b"}, {"1", "2"}},
+			consumed: 7,
+		},
+		{
+			name:     "quoted delimiter, new line and quote",
+			data:     "\"a,b\",\"c\nd\",\"e\"\"f\"\n",
+			records:  [][]string{{"a,b", "c\nd", "e\"f"}},
+			consumed: 19,
+		},
+		{
+			name:     "crlf and blank lines",
+			data:     "a,b\r\n\r\n1,2\r\n",
+			records:  [][]string{{"a", "b"}, {"1", "2"}},
+			consumed: 12,
+		},
+		{
+			name:     "no complete record",
+			data:     "a,\"long quoted\nfield",
+			records:  [][]string{},
+			consumed: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, consumed := parseCsv([]byte(tt.data), ',', '"', tt.final)
+			if !reflect.DeepEqual(records, tt.records) || consumed != tt.consumed {
+				t.Errorf("got %q %v, want %q %v", records, consumed, tt.records, tt.consumed)
+			}
+		})
+	}
+}
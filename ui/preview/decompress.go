@@ -0,0 +1,189 @@
+package preview
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/types"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// Compressions recognized from the extension of the key
+var compressionExtensions = map[string]string{
+	"gz":     "gzip",
+	"zst":    "zstd",
+	"bz2":    "bzip2",
+	"xz":     "xz",
+	"snappy": "snappy",
+	"lz4":    "lz4",
+}
+
+// Compressions recognized from the Content-Encoding of the object
+var contentEncodings = map[string]string{
+	"gzip":            "gzip",
+	"x-gzip":          "gzip",
+	"zstd":            "zstd",
+	"bzip2":           "bzip2",
+	"x-bzip2":         "bzip2",
+	"xz":              "xz",
+	"x-xz":            "xz",
+	"snappy":          "snappy",
+	"x-snappy-framed": "snappy",
+	"lz4":             "lz4",
+}
+
+// Returns the compression of the object, empty when it is not compressed, and the key without the
+// compression extension so logs.json.gz is shown like logs.json
+func getCompression(key, contentEncoding string) (string, string) {
+	if c, ok := compressionExtensions[strings.ToLower(icons.GetExtension(key))]; ok {
+		return c, strings.TrimSuffix(key, filepath.Ext(key))
+	}
+	if c, ok := contentEncodings[strings.ToLower(strings.TrimSpace(contentEncoding))]; ok {
+		return c, key
+	}
+
+	return "", key
+}
+
+// Reads the object in chunks, decompressing it when needed
+func newSource(m *types.UiModel, key string, info *api.ObjectInfo) source {
+	var src source = newRangeSource(m.Session, m.GetCurrentBucket(), key, info.Size)
+	if model.data != nil {
+		src = &memorySource{data: model.data}
+	}
+
+	compression, _ := getCompression(key, info.ContentEncoding)
+	if compression == "" {
+		return src
+	}
+
+	return newDecompressSource(src, compression)
+}
+
+func newDecompressor(compression string, r io.Reader) (io.Reader, error) {
+	switch compression {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case "bzip2":
+		return bzip2.NewReader(r), nil
+	case "xz":
+		return xz.NewReader(r)
+	case "snappy":
+		return snappy.NewReader(r), nil
+	case "lz4":
+		return lz4.NewReader(r), nil
+	}
+
+	return nil, fmt.Errorf("unknown compression %s", compression)
+}
+
+// Turns the chunks of a source back into a stream for the decompressors
+type chunkReader struct {
+	src     source
+	pending []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		b, err := r.src.Next()
+		if err != nil {
+			return 0, err
+		}
+		r.pending = b
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+// Hands out the decompressed object in chunks.  Offset still counts the compressed bytes read so the
+// viewers show how much of the object was downloaded.
+type decompressSource struct {
+	raw          source
+	compression  string
+	reader       io.Reader
+	decompressed int64
+	done         bool
+}
+
+func newDecompressSource(raw source, compression string) *decompressSource {
+	return &decompressSource{
+		raw:         raw,
+		compression: compression,
+	}
+}
+
+func (s *decompressSource) Next() ([]byte, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	// The decompressors read their header right away so they are created with the first chunk
+	if s.reader == nil {
+		r, err := newDecompressor(s.compression, &chunkReader{src: s.raw})
+		if err != nil {
+			s.done = true
+			return nil, fmt.Errorf("could not decompress the %s object: %w", s.compression, err)
+		}
+		s.reader = r
+	}
+
+	b := make([]byte, chunkSize)
+	n, err := io.ReadFull(s.reader, b)
+	s.decompressed += int64(n)
+
+	switch err {
+	case nil:
+		return b[:n], nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		s.close()
+		if n == 0 {
+			return nil, io.EOF
+		}
+		return b[:n], nil
+	}
+
+	s.close()
+	return nil, fmt.Errorf("could not decompress the %s object: %w", s.compression, err)
+}
+
+func (s *decompressSource) close() {
+	s.done = true
+	if c, ok := s.reader.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func (s *decompressSource) Offset() int64 {
+	return s.raw.Offset()
+}
+
+func (s *decompressSource) Done() bool {
+	return s.done
+}
+
+func (s *decompressSource) Compression() string {
+	return s.compression
+}
+
+// Bytes handed out so far, the whole decompressed size once Done
+func (s *decompressSource) Decompressed() int64 {
+	return s.decompressed
+}
@@ -0,0 +1,31 @@
+package preview
+
+import "testing"
+
+func TestGetCompression(t *testing.T) {
+	tests := []struct {
+		key, contentEncoding string
+		compression, name    string
+	}{
+		{"logs.json.gz", "", "gzip", "logs.json"},
+		{"a/b.CSV.ZST", "", "zstd", "a/b.CSV"},
+		{"data.bz2", "", "bzip2", "data"},
+		{"data.tar.xz", "", "xz", "data.tar"},
+		{"part.snappy", "", "snappy", "part"},
+		{"part.lz4", "", "lz4", "part"},
+		{"logs.json", "gzip", "gzip", "logs.json"},
+		{"logs.json", " X-GZIP ", "gzip", "logs.json"},
+		{"logs.json", "x-snappy-framed", "snappy", "logs.json"},
+		{"logs.gz", "zstd", "gzip", "logs"},
+		{"logs.json", "identity", "", "logs.json"},
+		{"logs.json", "", "", "logs.json"},
+		{"gz", "", "", "gz"},
+	}
+
+	for _, tt := range tests {
+		compression, name := getCompression(tt.key, tt.contentEncoding)
+		if compression != tt.compression || name != tt.name {
+			t.Errorf("getCompression(%q, %q) = %q, %q, want %q, %q", tt.key, tt.contentEncoding, compression, name, tt.compression, tt.name)
+		}
+	}
+}
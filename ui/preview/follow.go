@@ -0,0 +1,219 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"s3-viewer/api"
+	"s3-viewer/ui/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	followInterval = 2 * time.Second
+
+	// Bytes before the old end that are fetched again to tell an append from a rewrite
+	followOverlap = 256
+
+	// Following an object that is not loaded to the end skips to its last bytes like tail does
+	followTail = chunkSize
+
+	// Older lines are dropped while following so a growing object does not fill up memory
+	followKeep = 4 * 1024 * 1024
+)
+
+// Polls the object like tail -f.  Only the new bytes are fetched when the object grows, an object that
+// was rewritten rather than appended to is loaded again from its end.
+type follower struct {
+	session     *session.Session
+	bucket      string
+	key         string
+	src         *rangeSource
+	etag        string
+	start       int64 // Offset in the object of the first byte shown
+	isFollowing bool
+	isWaiting   bool // A tick or a check is on its way
+	toBottom    bool // Scroll to the end once the tail is loaded
+	message     string
+}
+
+type followTickMsg struct {
+	f *follower
+}
+
+type followCheckMsg struct {
+	f         *follower
+	info      *api.ObjectInfo
+	tail      []byte // The object from tailStart, only fetched when the ETag changed
+	tailStart int64
+	err       error
+}
+
+func newFollower(session *session.Session, bucket, key string, info *api.ObjectInfo, src *rangeSource) *follower {
+	return &follower{
+		session: session,
+		bucket:  bucket,
+		key:     key,
+		src:     src,
+		etag:    info.ETag,
+	}
+}
+
+func (f *follower) tick() tea.Cmd {
+	f.isWaiting = true
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return followTickMsg{f}
+	})
+}
+
+// Looks for changes to the object, loaded is the offset of the end of what is shown
+func (f *follower) check(loaded int64) tea.Cmd {
+	etag := f.etag
+	first := f.start
+	return func() tea.Msg {
+		info, err := api.GetObjectInfo(f.session, f.bucket, f.key)
+		if err != nil || info.ETag == etag || info.Size < loaded {
+			return followCheckMsg{f: f, info: info, err: err}
+		}
+
+		start := loaded - followOverlap
+		if start < first {
+			start = first
+		}
+		var tail []byte
+		if info.Size > start {
+			tail, err = api.GetObjectRange(f.session, f.bucket, f.key, start, info.Size-1)
+		}
+
+		return followCheckMsg{f, info, tail, start, err}
+	}
+}
+
+func (v *textViewer) toggleFollow() tea.Cmd {
+	f := v.follow
+	f.isFollowing = !f.isFollowing
+	f.message = ""
+	if !f.isFollowing {
+		return nil
+	}
+
+	if !v.eof {
+		return v.loadTail(v.size)
+	}
+
+	v.viewport.GotoBottom()
+	return v.keepFollowing()
+}
+
+// Polling starts once the end of the object is loaded
+func (v *textViewer) keepFollowing() tea.Cmd {
+	f := v.follow
+	if f == nil || !f.isFollowing || v.err != nil {
+		return nil
+	}
+	if !v.eof {
+		return v.loadMore()
+	}
+	if f.toBottom {
+		f.toBottom = false
+		v.dropBefore(0)
+		v.updateLines()
+		v.viewport.GotoBottom()
+	}
+	if f.isWaiting {
+		return nil
+	}
+
+	return f.tick()
+}
+
+// Replaces what is shown with the last bytes of the object, the first partial line is dropped once
+// they are loaded
+func (v *textViewer) loadTail(size int64) tea.Cmd {
+	f := v.follow
+	f.src = newRangeSource(f.session, f.bucket, f.key, size)
+	if size > followTail {
+		f.src.offset = size - followTail
+	}
+	f.start = f.src.offset
+	f.toBottom = true
+	v.src = f.src
+	v.size = size
+	v.data = nil
+	v.eof = false
+	v.isLoading = false
+	v.updateLines()
+
+	return v.keepFollowing()
+}
+
+// Drops the lines that end before n, the first line is only dropped when it does not start the object
+func (v *textViewer) dropBefore(n int) {
+	f := v.follow
+	if n == 0 && f.start == 0 {
+		return
+	}
+
+	i := bytes.IndexByte(v.data[n:], '\n')
+	if i < 0 {
+		return
+	}
+	n += i + 1
+	v.data = v.data[n:]
+	f.start += int64(n)
+}
+
+func (v *textViewer) handleFollowTick() tea.Cmd {
+	f := v.follow
+	if !f.isFollowing {
+		f.isWaiting = false
+		return nil
+	}
+
+	return f.check(f.start + int64(len(v.data)))
+}
+
+func (v *textViewer) handleFollowCheck(msg followCheckMsg) tea.Cmd {
+	f := v.follow
+	f.isWaiting = false
+	if !f.isFollowing {
+		return nil
+	}
+
+	if msg.err != nil {
+		f.message = fmt.Sprintf("check failed at %s", time.Now().Format(time.TimeOnly))
+		return v.keepFollowing()
+	}
+	if msg.info.ETag == f.etag {
+		return v.keepFollowing()
+	}
+	f.etag = msg.info.ETag
+
+	// The bytes before the old end have to be the same for the change to be an append.  A new ETag
+	// without new bytes means the object was written again with the same size.
+	loaded := f.start + int64(len(v.data))
+	overlap := loaded - msg.tailStart
+	if msg.info.Size <= loaded || msg.tailStart < f.start || int64(len(msg.tail)) <= overlap || !bytes.Equal(msg.tail[:overlap], v.data[msg.tailStart-f.start:]) {
+		f.message = fmt.Sprintf("rewritten at %s, reloaded", time.Now().Format(time.TimeOnly))
+		return v.loadTail(msg.info.Size)
+	}
+
+	added := msg.tail[overlap:]
+	atBottom := v.viewport.AtBottom()
+	v.data = append(v.data, added...)
+	if len(v.data) > followKeep {
+		v.dropBefore(len(v.data) - followKeep)
+	}
+	v.size = msg.info.Size
+	f.src.size = msg.info.Size
+	f.src.offset = msg.info.Size
+	v.updateLines()
+	if atBottom {
+		v.viewport.GotoBottom()
+	}
+	f.message = fmt.Sprintf("+%s at %s", utils.GetFriendlyByteDisplay(int64(len(added))), time.Now().Format(time.TimeOnly))
+
+	return v.keepFollowing()
+}
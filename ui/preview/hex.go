@@ -0,0 +1,514 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	hexBytesPerLine = 16
+
+	// Bytes kept around the screen, the reader fetches a bigger range anyway
+	hexWindowSize = 64 * 1024
+
+	// How much of the object is scanned by every read of a search
+	hexSearchChunk = 1024 * 1024
+
+	// A search gives up after this many bytes so a typo does not download a huge object
+	maxHexSearch = 256 * 1024 * 1024
+
+	// Bytes looked at to tell binary objects from text
+	sniffSize = 4096
+)
+
+// Prompts of the hex viewer
+const (
+	hexJumpPrompt       = "jump"
+	hexTextSearchPrompt = "text"
+	hexByteSearchPrompt = "hex"
+)
+
+var errHexSearchLimit = fmt.Errorf("not found in the next %s", utils.GetFriendlyByteDisplay(maxHexSearch))
+
+// A hex and ASCII dump of binary objects.  Only the bytes around the screen are loaded, jumping
+// around the object fetches the range with a ranged GET.
+type hexViewer struct {
+	reader      objectReader
+	open        openFunc
+	size        int64
+	offset      int64 // First byte on the screen, always at the start of a line
+	data        []byte
+	dataStart   int64
+	isLoading   bool
+	err         error
+	width       int
+	height      int
+	input       textinput.Model
+	prompt      string // Empty when no prompt is shown
+	pattern     []byte
+	matchAt     int64 // -1 when there is no match
+	isSearching bool
+	message     string
+}
+
+type hexDataMsg struct {
+	reader objectReader
+	start  int64
+	data   []byte
+	err    error
+}
+
+type hexSearchMsg struct {
+	reader objectReader
+	at     int64
+	err    error
+}
+
+// Objects with NUL bytes or lots of control characters and invalid UTF-8 at the start are binary
+func isBinary(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+
+	total, odd := 0, 0
+	for len(head) > 0 && utf8.FullRune(head) {
+		r, n := utf8.DecodeRune(head)
+		head = head[n:]
+		total++
+
+		switch {
+		case r == utf8.RuneError && n == 1:
+			odd++
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' && r != 0x1b:
+			odd++
+		}
+	}
+
+	return odd*10 > total
+}
+
+func newHexViewer(open openFunc, info *api.ObjectInfo) (*hexViewer, tea.Cmd) {
+	v := &hexViewer{
+		reader:  open(),
+		open:    open,
+		size:    info.Size,
+		input:   textinput.New(),
+		matchAt: -1,
+	}
+
+	return v, v.ensureLoaded()
+}
+
+// Loads the bytes on the screen unless they are there already
+func (v *hexViewer) ensureLoaded() tea.Cmd {
+	if v.isLoading || v.size == 0 {
+		return nil
+	}
+
+	end := v.offset + int64(v.getLineCount()*hexBytesPerLine)
+	if end > v.size {
+		end = v.size
+	}
+	if v.offset >= v.dataStart && end <= v.dataStart+int64(len(v.data)) {
+		return nil
+	}
+
+	start := v.offset - hexWindowSize/2
+	start -= start % hexBytesPerLine
+	if start < 0 {
+		start = 0
+	}
+
+	v.isLoading = true
+	reader := v.reader
+	return func() tea.Msg {
+		b := make([]byte, hexWindowSize)
+		n, err := reader.ReadAt(b, start)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		return hexDataMsg{reader, start, b[:n], err}
+	}
+}
+
+func (v *hexViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Lines of the dump that fit on the screen
+func (v *hexViewer) getLineCount() int {
+	n := v.height
+	if v.prompt != "" {
+		n--
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	return n
+}
+
+func (v *hexViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msg := msg.(type) {
+	case hexDataMsg:
+		if msg.reader != v.reader {
+			return nil
+		}
+		v.isLoading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return nil
+		}
+		v.data = msg.data
+		v.dataStart = msg.start
+
+	case hexSearchMsg:
+		if msg.reader != v.reader {
+			return nil
+		}
+		v.handleSearchResult(msg)
+
+	case tea.KeyMsg:
+		if v.prompt != "" {
+			cmds = append(cmds, v.handlePromptKey(msg))
+			break
+		}
+
+		v.message = ""
+		page := int64(v.getLineCount() * hexBytesPerLine)
+		switch msg.String() {
+		case "up", "k":
+			v.scrollTo(v.offset - hexBytesPerLine)
+
+		case "down", "j":
+			v.scrollTo(v.offset + hexBytesPerLine)
+
+		case "pgup", "b":
+			v.scrollTo(v.offset - page)
+
+		case "pgdown", "f", " ":
+			v.scrollTo(v.offset + page)
+
+		case "g", "home":
+			v.scrollTo(0)
+
+		case "G", "end":
+			v.scrollTo(v.size)
+
+		case ":":
+			cmds = append(cmds, v.showPrompt(hexJumpPrompt, "offset, e.g. 4096 or 0x1000"))
+
+		case "/":
+			cmds = append(cmds, v.showPrompt(hexTextSearchPrompt, "text"))
+
+		case "x":
+			cmds = append(cmds, v.showPrompt(hexByteSearchPrompt, "bytes, e.g. 50 4b 03 04"))
+
+		case "n":
+			cmds = append(cmds, v.search(false))
+
+		case "N":
+			cmds = append(cmds, v.search(true))
+
+		case "esc":
+			v.pattern = nil
+			v.matchAt = -1
+		}
+
+	default:
+		if v.prompt != "" {
+			var cmd tea.Cmd
+			v.input, cmd = v.input.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	cmds = append(cmds, v.ensureLoaded())
+
+	return tea.Batch(cmds...)
+}
+
+// Moves the screen so it starts at the line of offset, without scrolling past the end
+func (v *hexViewer) scrollTo(offset int64) {
+	lines := (v.size + hexBytesPerLine - 1) / hexBytesPerLine
+	last := (lines - int64(v.getLineCount())) * hexBytesPerLine
+	if offset > last {
+		offset = last
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	v.offset = offset - offset%hexBytesPerLine
+}
+
+func (v *hexViewer) showPrompt(prompt, placeholder string) tea.Cmd {
+	v.prompt = prompt
+	v.input.Prompt = prompt + ": "
+	v.input.Placeholder = placeholder
+	v.input.SetValue("")
+	v.input.Focus()
+
+	return textinput.Blink
+}
+
+func (v *hexViewer) handlePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		v.prompt = ""
+		v.input.Blur()
+		return nil
+
+	case "enter":
+		prompt := v.prompt
+		value := strings.TrimSpace(v.input.Value())
+		v.prompt = ""
+		v.input.Blur()
+		if value == "" {
+			return nil
+		}
+
+		switch prompt {
+		case hexJumpPrompt:
+			n, err := strconv.ParseInt(value, 0, 64)
+			if err != nil || n < 0 {
+				v.message = "not an offset"
+				return nil
+			}
+			if n >= v.size {
+				v.message = "past the end of the object"
+				n = v.size
+			}
+			v.scrollTo(n)
+
+		case hexTextSearchPrompt:
+			v.pattern = []byte(value)
+			v.matchAt = -1
+			return v.search(false)
+
+		case hexByteSearchPrompt:
+			p, err := parseHexPattern(value)
+			if err != nil {
+				v.message = "not a hex byte sequence"
+				return nil
+			}
+			v.pattern = p
+			v.matchAt = -1
+			return v.search(false)
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	return cmd
+}
+
+// Accepts bytes with or without spaces, e.g. "504b0304", "50 4b 03 04" or "0x504b0304"
+func parseHexPattern(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.ToLower(s), "0x")
+	s = strings.Join(strings.Fields(s), "")
+
+	return hex.DecodeString(s)
+}
+
+// Looks for the next match after the current one, or after the top of the screen when there is none
+func (v *hexViewer) search(backwards bool) tea.Cmd {
+	if len(v.pattern) == 0 || v.isSearching {
+		return nil
+	}
+
+	from := v.offset
+	if v.matchAt >= 0 {
+		from = v.matchAt + 1
+		if backwards {
+			from = v.matchAt
+		}
+	}
+
+	v.isSearching = true
+	reader := v.reader
+	searchReader := v.open()
+	pattern := v.pattern
+	return func() tea.Msg {
+		// The search reads far away from the screen so it gets its own reader and buffer
+		at, err := searchObject(searchReader, pattern, from, backwards)
+		return hexSearchMsg{reader, at, err}
+	}
+}
+
+func (v *hexViewer) handleSearchResult(msg hexSearchMsg) {
+	v.isSearching = false
+	switch {
+	case msg.err != nil:
+		v.message = msg.err.Error()
+	case msg.at < 0:
+		v.message = "no more matches"
+	default:
+		v.matchAt = msg.at
+		// Only move when the match is not on the screen already
+		if msg.at < v.offset || msg.at >= v.offset+int64(v.getLineCount()*hexBytesPerLine) {
+			v.scrollTo(msg.at - int64(v.getLineCount()/2*hexBytesPerLine))
+		}
+	}
+}
+
+// Returns where the first match at or after from starts, or the last one starting before from when
+// searching backwards.  -1 when there is no match.
+func searchObject(r objectReader, pattern []byte, from int64, backwards bool) (int64, error) {
+	// Chunks overlap so matches across their boundary are found
+	overlap := int64(len(pattern) - 1)
+	buf := make([]byte, hexSearchChunk+overlap)
+	scanned := int64(0)
+
+	if !backwards {
+		for pos := from; pos < r.Size(); pos += hexSearchChunk {
+			if scanned >= maxHexSearch {
+				return -1, errHexSearchLimit
+			}
+			n, err := r.ReadAt(buf, pos)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return -1, err
+			}
+			if i := bytes.Index(buf[:n], pattern); i >= 0 {
+				return pos + int64(i), nil
+			}
+			scanned += hexSearchChunk
+		}
+		return -1, nil
+	}
+
+	for end := from; end > 0; end -= hexSearchChunk {
+		if scanned >= maxHexSearch {
+			return -1, errHexSearchLimit
+		}
+		start := end - hexSearchChunk
+		if start < 0 {
+			start = 0
+		}
+		n, err := r.ReadAt(buf[:end-start+overlap], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return -1, err
+		}
+		if i := bytes.LastIndex(buf[:n], pattern); i >= 0 {
+			return start + int64(i), nil
+		}
+		scanned += hexSearchChunk
+	}
+
+	return -1, nil
+}
+
+func (v *hexViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.size == 0 {
+		return lineNumberStyle.Render("(empty)")
+	}
+
+	// Offsets get wider for objects bigger than 4GiB
+	digits := len(strconv.FormatInt(v.size, 16))
+	if digits < 8 {
+		digits = 8
+	}
+
+	lines := make([]string, 0, v.getLineCount())
+	for i := 0; i < v.getLineCount(); i++ {
+		start := v.offset + int64(i*hexBytesPerLine)
+		if start >= v.size {
+			break
+		}
+		lines = append(lines, v.renderLine(start, digits))
+	}
+
+	s := strings.Join(lines, "\n")
+	if v.prompt != "" {
+		s = lipgloss.JoinVertical(lipgloss.Left, s, searchStyle.Render(v.input.View()))
+	}
+
+	return lipgloss.NewStyle().Width(v.width).Height(v.height).MaxHeight(v.height).Render(s)
+}
+
+func (v *hexViewer) renderLine(start int64, digits int) string {
+	var hexPart, asciiPart strings.Builder
+
+	for i := int64(0); i < hexBytesPerLine; i++ {
+		if i == hexBytesPerLine/2 {
+			hexPart.WriteString(" ")
+		}
+
+		pos := start + i
+		if pos >= v.size {
+			hexPart.WriteString("   ")
+			continue
+		}
+		if pos < v.dataStart || pos >= v.dataStart+int64(len(v.data)) {
+			hexPart.WriteString(lineNumberStyle.Render("?? "))
+			asciiPart.WriteString(lineNumberStyle.Render("?"))
+			continue
+		}
+
+		b := v.data[pos-v.dataStart]
+		h := fmt.Sprintf("%02x", b)
+		a := "."
+		if b >= 0x20 && b < 0x7f {
+			a = string(rune(b))
+		}
+
+		switch {
+		case v.matchAt >= 0 && pos >= v.matchAt && pos < v.matchAt+int64(len(v.pattern)):
+			h = matchStyle.Render(h)
+			a = matchStyle.Render(a)
+		case b == 0:
+			h = lineNumberStyle.Render(h)
+			a = lineNumberStyle.Render(a)
+		}
+		hexPart.WriteString(h + " ")
+		asciiPart.WriteString(a)
+	}
+
+	return fmt.Sprintf("%s  %s %s", lineNumberStyle.Render(fmt.Sprintf("%0*x", digits, start)), hexPart.String(), asciiPart.String())
+}
+
+func (v *hexViewer) status() string {
+	s := fmt.Sprintf("0x%x of 0x%x", v.offset, v.size)
+	if v.size > 0 {
+		s = fmt.Sprintf("%s • %v%%", s, v.offset*100/v.size)
+	}
+	if v.isSearching {
+		s = fmt.Sprintf("searching • %s", s)
+	} else if v.matchAt >= 0 {
+		s = fmt.Sprintf("match at 0x%x • %s", v.matchAt, s)
+	}
+	if v.message != "" {
+		s = fmt.Sprintf("%s • %s", v.message, s)
+	}
+
+	return s
+}
+
+func (v *hexViewer) help() string {
+	return help.GetHexPreviewHelp(v.prompt != "", len(v.pattern) > 0)
+}
+
+func (v *hexViewer) isCapturingKeys() bool {
+	return v.prompt != "" || len(v.pattern) > 0
+}
@@ -0,0 +1,67 @@
+package preview
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseHexPattern(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  []byte
+		isErr bool
+	}{
+		{"504b0304", []byte{0x50, 0x4b, 0x03, 0x04}, false},
+		{"50 4b 03 04", []byte{0x50, 0x4b, 0x03, 0x04}, false},
+		{"0x504B0304", []byte{0x50, 0x4b, 0x03, 0x04}, false},
+		{" ff\t00 ", []byte{0xff, 0x00}, false},
+		{"504", nil, true},
+		{"zz", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHexPattern(tt.in)
+		if (err != nil) != tt.isErr || (!tt.isErr && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseHexPattern(%q) = %x, %v, want %x", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestSearchObject(t *testing.T) {
+	// Two chunks with matches on both sides of the boundary and one across it
+	data := make([]byte, 2*hexSearchChunk)
+	pattern := []byte("needle")
+	copy(data[10:], pattern)
+	copy(data[hexSearchChunk-3:], pattern)
+	copy(data[hexSearchChunk+100:], pattern)
+	r := bytes.NewReader(data)
+
+	tests := []struct {
+		name      string
+		pattern   []byte
+		from      int64
+		backwards bool
+		want      int64
+	}{
+		{"first", pattern, 0, false, 10},
+		{"at from", pattern, 10, false, 10},
+		{"across chunks", pattern, 11, false, hexSearchChunk - 3},
+		{"second chunk", pattern, hexSearchChunk - 2, false, hexSearchChunk + 100},
+		{"none after", pattern, hexSearchChunk + 101, false, -1},
+		{"backwards", pattern, int64(len(data)), true, hexSearchChunk + 100},
+		{"backwards across chunks", pattern, hexSearchChunk + 100, true, hexSearchChunk - 3},
+		{"backwards first", pattern, hexSearchChunk - 3, true, 10},
+		{"none before", pattern, 10, true, -1},
+		{"missing", []byte("haystack"), 0, false, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := searchObject(r, tt.pattern, tt.from, tt.backwards)
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package preview
+
+import (
+	"mime"
+	"os"
+	"path/filepath"
+	"s3-viewer/ui/components/icons"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Objects larger than this are shown as plain text, every chunk re-lexes everything loaded so far
+const maxHighlightSize = 1024 * 1024
+
+var (
+	// Extensions chroma does not know or guesses wrong
+	languageOverrides = map[string]string{
+		"tfstate": "json",
+		"tfvars":  "terraform",
+		"jsonl":   "json",
+		"ndjson":  "json",
+		"ipynb":   "json",
+		"sql":     "sql",
+	}
+
+	// Themes cycled through with T, the first one can be set with S3_VIEWER_THEME
+	themes = []string{"monokai", "dracula", "github-dark", "nord", "solarized-dark", "github"}
+)
+
+// Finds the lexer from the extension of the key first and the content type second.  Nil means plain text.
+// Only the extension lookup is shared with the icons, their map is keyed by icon names and holds glyphs,
+// not languages, so chroma's own filename patterns decide and languageOverrides fills in what they miss.
+func getLexer(key, contentType string) chroma.Lexer {
+	ext := strings.ToLower(icons.GetExtension(key))
+	if name, ok := languageOverrides[ext]; ok {
+		return lexers.Get(name)
+	}
+
+	if l := lexers.Match(filepath.Base(key)); l != nil {
+		return l
+	}
+
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		if l := lexers.MatchMimeType(mt); l != nil {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func getInitialTheme() int {
+	t := os.Getenv("S3_VIEWER_THEME")
+	if t == "" {
+		return 0
+	}
+
+	for i, n := range themes {
+		if n == t {
+			return i
+		}
+	}
+
+	// Any other chroma style works as well, it is just not part of the cycle
+	if _, ok := styles.Registry[t]; ok {
+		themes = append([]string{t}, themes...)
+	}
+
+	return 0
+}
+
+// Highlights text and returns it split into lines, each line carries its own escape codes so lines can
+// be truncated or wrapped on their own.  Nil when the text could not be highlighted.
+func highlightLines(lexer chroma.Lexer, theme string, text string) []string {
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, text)
+	if err != nil {
+		return nil
+	}
+
+	style := styles.Get(theme)
+	formatter := formatters.TTY256
+
+	lines := chroma.SplitTokensIntoLines(it.Tokens())
+	result := make([]string, len(lines))
+	for i, tokens := range lines {
+		for j := range tokens {
+			tokens[j].Value = sanitizeLine(strings.TrimSuffix(tokens[j].Value, "\n"))
+		}
+
+		var b strings.Builder
+		if err := formatter.Format(&b, style, chroma.Literator(tokens...)); err != nil {
+			return nil
+		}
+		result[i] = b.String()
+	}
+
+	return result
+}
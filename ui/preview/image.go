@@ -0,0 +1,374 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"os"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// Images are downloaded whole to be decoded
+	maxImageSize = 32 * 1024 * 1024
+
+	// Cell size assumed when the terminal does not report it
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+
+	// Id of the image placed with the kitty protocol, so it can be replaced and deleted
+	kittyImageID = 5333
+)
+
+// How images are put on the screen
+const (
+	kittyProtocol  = "kitty"
+	sixelProtocol  = "sixel"
+	blocksProtocol = "blocks"
+)
+
+// Shows images with the kitty graphics protocol or sixel when the terminal supports it, otherwise with
+// half blocks in 24 bit color.  The terminal protocols are handed to Bubble Tea as a scroll area once the
+// body has been rendered, the body itself is left blank for them.
+type imageViewer struct {
+	size       int64
+	protocol   string
+	img        image.Image
+	format     string
+	isLoading  bool
+	err        error
+	width      int
+	height     int
+	blocks     string // Half block rendering of the last size
+	blocksSize [2]int
+	drawnSize  [2]int // Size of the body the terminal last drew the image for
+	closed     bool
+}
+
+type imageLoadedMsg struct {
+	viewer *imageViewer
+	img    image.Image
+	format string
+	err    error
+}
+
+type imageDrawMsg struct {
+	viewer *imageViewer
+	size   [2]int
+}
+
+func isImage(key, contentType string) bool {
+	if icons.IsImage(key) {
+		return true
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "image/png", "image/jpeg", "image/gif", "image/webp", "image/bmp", "image/tiff":
+		return true
+	}
+
+	return false
+}
+
+// S3_VIEWER_IMAGES picks the protocol, otherwise it is guessed from the terminal
+func getImageProtocol() string {
+	switch p := strings.ToLower(os.Getenv("S3_VIEWER_IMAGES")); p {
+	case kittyProtocol, sixelProtocol, blocksProtocol:
+		return p
+	}
+
+	// Graphics do not make it through tmux
+	if os.Getenv("TMUX") != "" {
+		return blocksProtocol
+	}
+
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || strings.Contains(term, "kitty") || program == "ghostty":
+		return kittyProtocol
+	case program == "WezTerm" || program == "iTerm.app" || strings.Contains(term, "foot") ||
+		strings.Contains(term, "mlterm") || strings.Contains(term, "contour") || strings.Contains(term, "sixel"):
+		return sixelProtocol
+	}
+
+	return blocksProtocol
+}
+
+func newImageViewer(open openFunc, info *api.ObjectInfo) (*imageViewer, tea.Cmd) {
+	v := &imageViewer{
+		size:      info.Size,
+		protocol:  getImageProtocol(),
+		isLoading: true,
+	}
+
+	if info.Size > maxImageSize {
+		v.isLoading = false
+		v.err = fmt.Errorf("images bigger than %s are not previewed", utils.GetFriendlyByteDisplay(maxImageSize))
+		return v, nil
+	}
+
+	r := open()
+	return v, func() tea.Msg {
+		b := make([]byte, info.Size)
+		if _, err := r.ReadAt(b, 0); err != nil && !errors.Is(err, io.EOF) {
+			return imageLoadedMsg{viewer: v, err: err}
+		}
+		img, format, err := image.Decode(bytes.NewReader(b))
+		return imageLoadedMsg{v, img, format, err}
+	}
+}
+
+func (v *imageViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *imageViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case imageLoadedMsg:
+		if msg.viewer != v {
+			return nil
+		}
+		v.isLoading = false
+		if msg.err != nil {
+			v.err = fmt.Errorf("could not decode the image: %w", msg.err)
+			return nil
+		}
+		v.img = msg.img
+		v.format = msg.format
+
+	case imageDrawMsg:
+		if msg.viewer == v && msg.size == v.drawnSize && !v.closed {
+			return v.draw()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		if msg.String() == "r" {
+			v.drawnSize = [2]int{}
+		}
+	}
+
+	// Drawn a moment later so it lands on top of the blank body rather than being painted over
+	size := [2]int{v.width, v.height}
+	if v.protocol != blocksProtocol && v.img != nil && v.drawnSize != size {
+		v.drawnSize = size
+		return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+			return imageDrawMsg{v, size}
+		})
+	}
+
+	return nil
+}
+
+// Returns the pixel size the image is scaled to and the cells it covers.  The first line of the body
+// stays with the renderer, see paint.
+func (v *imageViewer) layout() (int, int, int, int) {
+	cellWidth, cellHeight := getCellSize()
+	b := v.img.Bounds()
+
+	scale := float64(v.width*cellWidth) / float64(b.Dx())
+	if s := float64((v.height-1)*cellHeight) / float64(b.Dy()); s < scale {
+		scale = s
+	}
+	// Small images are drawn at their own size
+	if scale > 1 {
+		scale = 1
+	}
+
+	w := int(float64(b.Dx()) * scale)
+	h := int(float64(b.Dy()) * scale)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	return w, h, (w + cellWidth - 1) / cellWidth, (h + cellHeight - 1) / cellHeight
+}
+
+func (v *imageViewer) draw() tea.Cmd {
+	if v.height < 2 {
+		return nil
+	}
+	w, h, cols, rows := v.layout()
+	scaled := scaleImage(v.img, w, h)
+
+	var s strings.Builder
+	// The body starts on the second line, below the header, and the image below its first line
+	s.WriteString("\x1b7")
+	fmt.Fprintf(&s, "\x1b[%d;%dH", 3+(v.height-1-rows)/2, 1+(v.width-cols)/2)
+	switch v.protocol {
+	case kittyProtocol:
+		s.WriteString(getKittyDelete())
+		writeKittyImage(&s, scaled)
+	case sixelProtocol:
+		writeSixel(&s, scaled)
+	}
+	s.WriteString("\x1b8")
+
+	return v.paint(s.String())
+}
+
+// Escapes are written by Bubble Tea under the same lock as its frames, as the first line of a scroll
+// area over the body.  The renderer leaves the lines of the area alone until it is cleared.  It counts
+// lines from 0 and the scrolling region from 1, so the area starts on the second line of the body to
+// keep the header out of the region.
+func (v *imageViewer) paint(escapes string) tea.Cmd {
+	return tea.SyncScrollArea([]string{escapes}, 2, v.height+1)
+}
+
+// Images placed with the kitty protocol stay on the screen until they are deleted, the body lines are
+// given back to the renderer which repaints them over what is left of a sixel image
+func (v *imageViewer) close() tea.Cmd {
+	v.closed = true
+	if v.protocol == blocksProtocol {
+		return nil
+	}
+
+	var del tea.Cmd
+	if v.protocol == kittyProtocol {
+		del = v.paint(getKittyDelete())
+	}
+
+	return tea.Sequence(del, tea.ClearScrollArea)
+}
+
+func getKittyDelete() string {
+	return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID)
+}
+
+// Sends the image as PNG in base64 chunks.  q=2 keeps the terminal from answering, the answers would
+// show up as key presses.
+func writeKittyImage(s *strings.Builder, img image.Image) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return
+	}
+
+	data := base64.StdEncoding.EncodeToString(b.Bytes())
+	for i := 0; i < len(data); i += 4096 {
+		end := i + 4096
+		more := 1
+		if end >= len(data) {
+			end = len(data)
+			more = 0
+		}
+
+		if i == 0 {
+			fmt.Fprintf(s, "\x1b_Ga=T,f=100,q=2,C=1,i=%d,m=%d;%s\x1b\\", kittyImageID, more, data[i:end])
+		} else {
+			fmt.Fprintf(s, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+}
+
+func scaleImage(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return dst
+}
+
+// Every cell shows two pixels, the top one in the foreground of ▀ and the bottom one in the background.
+// Transparent pixels come out black.
+func renderBlocks(img image.Image, width, height int) string {
+	b := img.Bounds()
+	scale := float64(width) / float64(b.Dx())
+	if s := float64(height*2) / float64(b.Dy()); s < scale {
+		scale = s
+	}
+
+	w := int(float64(b.Dx()) * scale)
+	h := int(float64(b.Dy()) * scale)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	scaled := scaleImage(img, w, h)
+
+	var s strings.Builder
+	for y := 0; y < h; y += 2 {
+		if y > 0 {
+			s.WriteString("\n")
+		}
+		for x := 0; x < w; x++ {
+			top := scaled.RGBAAt(x, y)
+			fmt.Fprintf(&s, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+			if y+1 < h {
+				bottom := scaled.RGBAAt(x, y+1)
+				fmt.Fprintf(&s, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+			} else {
+				s.WriteString("\x1b[49m")
+			}
+			s.WriteString("▀")
+		}
+		s.WriteString("\x1b[0m")
+	}
+
+	return s.String()
+}
+
+func (v *imageViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.img == nil {
+		return lineNumberStyle.Render("Loading")
+	}
+
+	// The terminal draws over a blank body
+	if v.protocol != blocksProtocol {
+		return lipgloss.NewStyle().Width(v.width).Height(v.height).Render("")
+	}
+
+	size := [2]int{v.width, v.height}
+	if v.blocksSize != size {
+		v.blocks = renderBlocks(v.img, v.width, v.height)
+		v.blocksSize = size
+	}
+
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Center, v.blocks)
+}
+
+func (v *imageViewer) status() string {
+	if v.img == nil {
+		return ""
+	}
+
+	b := v.img.Bounds()
+	return fmt.Sprintf("%s • %d×%d • %s", v.format, b.Dx(), b.Dy(), v.protocol)
+}
+
+func (v *imageViewer) help() string {
+	return help.GetImagePreviewHelp(v.protocol != blocksProtocol)
+}
+
+func (v *imageViewer) isCapturingKeys() bool {
+	return false
+}
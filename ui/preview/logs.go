@@ -0,0 +1,138 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"s3-viewer/api"
+	"s3-viewer/ui/types"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Log objects are read whole, they are parsed into records for the log viewers
+const maxLogRecords = 200000
+
+// Picks the viewer for several objects opened together
+func newLogViewer(m *types.UiModel, keys []string) (viewer, tea.Cmd) {
+	if isCloudTrail(keys[0]) {
+		return newCloudTrailViewer(m, keys)
+	}
+
+	return newAccessLogViewer(m, keys)
+}
+
+// Reads a whole log object, decompressing it by its extension
+func readLogObject(m *types.UiModel, key string) ([]byte, error) {
+	if model.data != nil {
+		return model.data, nil
+	}
+
+	b, err := api.GetObject(m.Session, m.GetCurrentBucket(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, _ := getCompression(key, "")
+	if compression == "" {
+		return b, nil
+	}
+
+	r, err := newDecompressor(compression, bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress %s: %w", key, err)
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	return io.ReadAll(r)
+}
+
+// A filter is made of terms that all have to match.  field:value looks for value in the field (status:4
+// matches from the start so it finds the 4xx), field=value wants the field to be exactly value, anything
+// else is looked for in the whole record.  A leading - keeps the records that do not match.  Values with
+// spaces can be "quoted".
+type logFilterTerm struct {
+	field  string
+	value  string
+	exact  bool
+	negate bool
+}
+
+func parseLogFilter(f string) []logFilterTerm {
+	terms := make([]logFilterTerm, 0)
+	for _, t := range splitLogFilter(f) {
+		term := logFilterTerm{}
+		if strings.HasPrefix(t, "-") && len(t) > 1 {
+			term.negate = true
+			t = t[1:]
+		}
+
+		if i := strings.IndexAny(t, ":="); i > 0 && !strings.ContainsAny(t[:i], "\" ") {
+			term.field = strings.ToLower(t[:i])
+			term.exact = t[i] == '='
+			t = t[i+1:]
+		}
+		term.value = strings.ToLower(strings.Trim(t, "\""))
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// Splits on spaces outside of quotes
+func splitLogFilter(f string) []string {
+	terms := make([]string, 0)
+	var b strings.Builder
+	quoted := false
+	for _, r := range f {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if b.Len() > 0 {
+				terms = append(terms, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		terms = append(terms, b.String())
+	}
+
+	return terms
+}
+
+// Quotes values that would otherwise be split into several terms
+func quoteLogFilterValue(v string) string {
+	if strings.ContainsAny(v, " \t\"") {
+		return fmt.Sprintf("\"%s\"", strings.ReplaceAll(v, "\"", ""))
+	}
+
+	return v
+}
+
+// field returns the value of a field of the record, ok is false when the record has no such field
+func (t logFilterTerm) matches(field func(name string) (string, bool), all string) bool {
+	var matched bool
+	if t.field == "" {
+		matched = strings.Contains(strings.ToLower(all), t.value)
+	} else if v, ok := field(t.field); ok {
+		v = strings.ToLower(v)
+		if t.exact {
+			matched = v == t.value
+		} else if t.field == "status" {
+			matched = strings.HasPrefix(v, t.value)
+		} else {
+			matched = strings.Contains(v, t.value)
+		}
+	}
+
+	return matched != t.negate
+}
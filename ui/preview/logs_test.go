@@ -0,0 +1,68 @@
+package preview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLogFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []logFilterTerm
+	}{
+		{"", []logFilterTerm{}},
+		{"photo", []logFilterTerm{{value: "photo"}}},
+		{"Status:4", []logFilterTerm{{field: "status", value: "4"}}},
+		{"op=REST.GET.OBJECT", []logFilterTerm{{field: "op", value: "rest.get.object", exact: true}}},
+		{"-status:2 key:logs", []logFilterTerm{{field: "status", value: "2", negate: true}, {field: "key", value: "logs"}}},
+		{`agent:"aws cli"`, []logFilterTerm{{field: "agent", value: "aws cli"}}},
+		{`"a:b c"`, []logFilterTerm{{value: "a:b c"}}},
+		{"-", []logFilterTerm{{value: "-"}}},
+		{":x", []logFilterTerm{{value: ":x"}}},
+		{"  a   b  ", []logFilterTerm{{value: "a"}, {value: "b"}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseLogFilter(tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLogFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestLogFilterMatches(t *testing.T) {
+	fields := map[string]string{"status": "404", "key": "logs/app.log"}
+	field := func(name string) (string, bool) {
+		v, ok := fields[name]
+		return v, ok
+	}
+	all := "404 logs/app.log GET"
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{"get", true},
+		{"put", false},
+		{"status:4", true},
+		{"status:04", false},
+		{"status=404", true},
+		{"key:app", true},
+		{"key=app", false},
+		{"-key:app", false},
+		{"-status:2", true},
+		{"missing:x", false},
+		{"-missing:x", true},
+		{"status:4 key:other", false},
+	}
+
+	for _, tt := range tests {
+		got := true
+		for _, term := range parseLogFilter(tt.filter) {
+			got = got && term.matches(field, all)
+		}
+		if got != tt.want {
+			t.Errorf("%q matched %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package preview
+
+import (
+	"fmt"
+	"mime"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/types"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+)
+
+// Bigger markdown objects are shown as plain text, they are rendered in one go
+const maxMarkdownSize = 4 * 1024 * 1024
+
+// Renders markdown objects with headings, lists, code blocks and tables.  The source is loaded by a
+// text viewer which also shows it when switching to the raw source.
+type markdownViewer struct {
+	text       *textViewer
+	raw        bool
+	viewport   viewport.Model
+	width      int
+	rendered   string
+	renderedAt int // Width the markdown was rendered for, 0 when it has to be rendered again
+	err        error
+}
+
+func isMarkdown(key, contentType string) bool {
+	switch strings.ToLower(icons.GetExtension(key)) {
+	case "md", "markdown", "mdown", "mkd":
+		return true
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "text/markdown" || mt == "text/x-markdown"
+}
+
+func newMarkdownViewer(src source, key string, info *api.ObjectInfo) (*markdownViewer, tea.Cmd) {
+	text, cmd := newTextViewer(src, key, info)
+
+	return &markdownViewer{
+		text:     text,
+		viewport: viewport.New(0, 0),
+	}, cmd
+}
+
+func (v *markdownViewer) setSize(width, height int) {
+	v.text.setSize(width, height)
+
+	if height < 1 {
+		height = 1
+	}
+	v.viewport.Height = height
+	if width != v.width {
+		v.width = width
+		v.viewport.Width = width
+		v.renderedAt = 0
+	}
+}
+
+func (v *markdownViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "m" && !v.text.isSearching {
+		v.raw = !v.raw
+		return nil
+	}
+
+	if v.raw {
+		return v.text.update(m, msg)
+	}
+
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case textChunkMsg:
+		cmds = append(cmds, v.text.update(m, msg))
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "g", "home":
+			v.viewport.GotoTop()
+
+		case "G", "end":
+			v.viewport.GotoBottom()
+
+		default:
+			var cmd tea.Cmd
+			v.viewport, cmd = v.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	// The whole source is needed before it can be rendered
+	if !v.text.eof {
+		cmds = append(cmds, v.text.loadMore())
+	} else if v.renderedAt == 0 && v.width > 0 {
+		v.render()
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (v *markdownViewer) render() {
+	v.renderedAt = v.width
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle("dark"),
+		glamour.WithWordWrap(v.width-4),
+	)
+	if err == nil {
+		v.rendered, err = r.Render(string(v.text.data))
+	}
+	if err != nil {
+		v.err = fmt.Errorf("could not render the markdown: %w", err)
+		return
+	}
+
+	v.viewport.SetContent(strings.TrimRight(v.rendered, "\n"))
+}
+
+func (v *markdownViewer) view() string {
+	if v.raw {
+		return v.text.view()
+	}
+	if v.text.err != nil {
+		return errorStyle.Render(v.text.err.Error())
+	}
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.renderedAt == 0 {
+		return lineNumberStyle.Render("Loading")
+	}
+
+	return v.viewport.View()
+}
+
+func (v *markdownViewer) status() string {
+	if v.raw {
+		return fmt.Sprintf("raw • %s", v.text.status())
+	}
+	if v.renderedAt == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("markdown • %.f%%", v.viewport.ScrollPercent()*100)
+}
+
+func (v *markdownViewer) help() string {
+	if v.raw && v.text.isCapturingKeys() {
+		return v.text.help()
+	}
+
+	return help.GetMarkdownPreviewHelp(v.raw)
+}
+
+func (v *markdownViewer) isCapturingKeys() bool {
+	return v.raw && v.text.isCapturingKeys()
+}
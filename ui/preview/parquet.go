@@ -0,0 +1,616 @@
+package preview
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/components/table"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	pqsource "github.com/xitongsys/parquet-go/source"
+	pqtypes "github.com/xitongsys/parquet-go/types"
+)
+
+const (
+	// Rows decoded for the rows tab
+	maxParquetRows = 100
+
+	parquetSchemaTab    = 0
+	parquetRowGroupsTab = 1
+	parquetRowsTab      = 2
+)
+
+var parquetTabs = []string{"Schema", "Row groups", "Rows"}
+
+// Shows the footer of a Parquet file (schema, row groups, compression and statistics) and the first
+// rows.  Everything is read with ranged requests.  The rows are only decoded once their tab is opened,
+// which reads the start of every column chunk of the first row group.
+type parquetViewer struct {
+	reader         objectReader
+	isLoading      bool
+	err            error
+	pr             *reader.ParquetReader
+	tab            int
+	schemaTable    *table.Model
+	rowGroupsTable *table.Model
+	rowsTable      *table.Model
+	rowsLoading    bool
+	rowsErr        error
+	width          int
+	height         int
+}
+
+type parquetFooterMsg struct {
+	reader objectReader
+	pr     *reader.ParquetReader
+	err    error
+}
+
+type parquetRowsMsg struct {
+	reader objectReader
+	header []string
+	rows   []table.Row
+	err    error
+}
+
+// Adapts the object reader to the file interface of the parquet library, which opens a file per column
+type parquetFile struct {
+	objectReader
+	open openFunc
+}
+
+func (f *parquetFile) Open(name string) (pqsource.ParquetFile, error) {
+	if name != "" {
+		return nil, fmt.Errorf("column chunks in other files (%s) are not supported", name)
+	}
+	return &parquetFile{f.open(), f.open}, nil
+}
+
+func (f *parquetFile) Create(name string) (pqsource.ParquetFile, error) {
+	return nil, errors.New("parquet preview is read only")
+}
+
+func (f *parquetFile) Write(p []byte) (int, error) {
+	return 0, errors.New("parquet preview is read only")
+}
+
+func (f *parquetFile) Close() error {
+	return nil
+}
+
+func isParquet(key, contentType string) bool {
+	if strings.EqualFold(icons.GetExtension(key), "parquet") {
+		return true
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "application/vnd.apache.parquet" || mt == "application/x-parquet"
+}
+
+func newParquetViewer(open openFunc) (*parquetViewer, tea.Cmd) {
+	v := &parquetViewer{
+		reader:    open(),
+		isLoading: true,
+	}
+
+	r := v.reader
+	return v, func() tea.Msg {
+		pr, err := openParquet(r, open)
+		return parquetFooterMsg{r, pr, err}
+	}
+}
+
+// The parquet library panics on files it does not understand
+func openParquet(r objectReader, open openFunc) (pr *reader.ParquetReader, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("could not read the parquet footer: %v", p)
+		}
+	}()
+
+	if r.Size() < 12 {
+		return nil, errors.New("the object is too small to be a parquet file")
+	}
+	magic := make([]byte, 4)
+	if _, err := r.ReadAt(magic, r.Size()-4); err != nil {
+		return nil, err
+	}
+	if string(magic) != "PAR1" {
+		return nil, errors.New("the object is not a parquet file, the PAR1 footer is missing")
+	}
+
+	return reader.NewParquetColumnReader(&parquetFile{r, open}, 1)
+}
+
+func (v *parquetViewer) setSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *parquetViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case parquetFooterMsg:
+		if msg.reader != v.reader {
+			return nil
+		}
+		v.isLoading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return nil
+		}
+
+		v.pr = msg.pr
+		v.schemaTable = newParquetSchemaTable(msg.pr)
+		v.rowGroupsTable = newParquetRowGroupsTable(msg.pr)
+
+	case parquetRowsMsg:
+		if msg.reader != v.reader {
+			return nil
+		}
+		v.rowsLoading = false
+		if msg.err != nil {
+			v.rowsErr = msg.err
+			return nil
+		}
+		v.rowsTable = newSizedTable(msg.header, msg.rows)
+		v.rowsTable.SetFooterInfo(fmt.Sprintf("first %v of %v rows", len(msg.rows), v.pr.GetNumRows()))
+
+	case tea.KeyMsg:
+		if v.pr == nil {
+			return nil
+		}
+
+		switch msg.String() {
+		case "tab":
+			v.tab = (v.tab + 1) % len(parquetTabs)
+		case "shift+tab":
+			v.tab = (v.tab + len(parquetTabs) - 1) % len(parquetTabs)
+		case "1", "2", "3":
+			v.tab = int(msg.String()[0] - '1')
+		default:
+			if t := v.currentTable(); t != nil {
+				var cmd tea.Cmd
+				_, cmd = t.Update(msg)
+				return cmd
+			}
+			return nil
+		}
+
+		if v.tab == parquetRowsTab {
+			return v.loadRows()
+		}
+	}
+
+	return nil
+}
+
+func (v *parquetViewer) loadRows() tea.Cmd {
+	if v.rowsTable != nil || v.rowsLoading || v.rowsErr != nil {
+		return nil
+	}
+
+	v.rowsLoading = true
+	pr := v.pr
+	r := v.reader
+	return func() tea.Msg {
+		header, rows, err := readParquetRows(pr, maxParquetRows)
+		return parquetRowsMsg{r, header, rows, err}
+	}
+}
+
+func (v *parquetViewer) currentTable() *table.Model {
+	switch v.tab {
+	case parquetSchemaTab:
+		return v.schemaTable
+	case parquetRowGroupsTab:
+		return v.rowGroupsTable
+	default:
+		return v.rowsTable
+	}
+}
+
+func newParquetSchemaTable(pr *reader.ParquetReader) *table.Model {
+	header := []string{"Name", "Type", "Logical type", "Repetition"}
+	rows := make([]table.Row, 0)
+
+	// Children follow their parent in the list, remaining child counts give the depth
+	remaining := make([]int32, 0)
+	for i, e := range pr.SchemaHandler.SchemaElements {
+		depth := len(remaining)
+		if depth > 0 {
+			remaining[depth-1]--
+		}
+
+		if i > 0 {
+			physical := ""
+			if e.Type != nil {
+				physical = e.Type.String()
+				if e.GetTypeLength() > 0 {
+					physical = fmt.Sprintf("%s(%v)", physical, e.GetTypeLength())
+				}
+			}
+
+			repetition := ""
+			if e.RepetitionType != nil {
+				repetition = e.RepetitionType.String()
+			}
+
+			rows = append(rows, table.Row{
+				strings.Repeat("  ", depth-1) + pr.SchemaHandler.GetExName(i),
+				physical,
+				getLogicalType(e),
+				repetition,
+			})
+		}
+
+		if e.GetNumChildren() > 0 {
+			remaining = append(remaining, e.GetNumChildren())
+		}
+		for len(remaining) > 0 && remaining[len(remaining)-1] == 0 {
+			remaining = remaining[:len(remaining)-1]
+		}
+	}
+
+	t := newSizedTable(header, rows)
+	t.SetFooterInfo(pr.Footer.GetCreatedBy())
+
+	return t
+}
+
+func newParquetRowGroupsTable(pr *reader.ParquetReader) *table.Model {
+	header := []string{"Group", "Column", "Codec", "Values", "Nulls", "Min", "Max", "Compressed", "Uncompressed"}
+	rows := make([]table.Row, 0)
+
+	for i, rg := range pr.Footer.GetRowGroups() {
+		for _, c := range rg.GetColumns() {
+			md := c.GetMetaData()
+			if md == nil {
+				continue
+			}
+
+			path := getParquetColumnName(pr, md.GetPathInSchema())
+			e := getParquetElement(pr, md.GetPathInSchema())
+
+			nulls, min, max := "", "", ""
+			if s := md.GetStatistics(); s != nil {
+				if s.NullCount != nil {
+					nulls = strconv.FormatInt(*s.NullCount, 10)
+				}
+				minValue, maxValue := s.MinValue, s.MaxValue
+				if minValue == nil && maxValue == nil {
+					minValue, maxValue = s.Min, s.Max
+				}
+				min = formatParquetStatistic(minValue, md.GetType(), e)
+				max = formatParquetStatistic(maxValue, md.GetType(), e)
+			}
+
+			rows = append(rows, table.Row{
+				strconv.Itoa(i + 1),
+				path,
+				md.GetCodec().String(),
+				strconv.FormatInt(md.GetNumValues(), 10),
+				nulls,
+				min,
+				max,
+				utils.GetFriendlyByteDisplay(md.GetTotalCompressedSize()),
+				utils.GetFriendlyByteDisplay(md.GetTotalUncompressedSize()),
+			})
+		}
+	}
+
+	return newSizedTable(header, rows)
+}
+
+// Decodes the first n rows column by column, repeated values of a row are shown as a list
+func readParquetRows(pr *reader.ParquetReader, n int64) (header []string, rows []table.Row, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("could not decode the rows: %v", p)
+		}
+	}()
+
+	if total := pr.GetNumRows(); n > total {
+		n = total
+	}
+
+	columns := pr.SchemaHandler.ValueColumns
+	header = make([]string, len(columns))
+	rows = make([]table.Row, n)
+	for i := range rows {
+		rows[i] = make(table.Row, len(columns))
+	}
+
+	for i, path := range columns {
+		inPath := common.StrToPath(path)[1:]
+		header[i] = getParquetColumnName(pr, inPath)
+		e := getParquetElement(pr, inPath)
+		maxRl, _ := pr.SchemaHandler.MaxRepetitionLevel(common.StrToPath(path))
+
+		values, rls, _, err := pr.ReadColumnByIndex(int64(i), n)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		row := -1
+		cells := make([][]string, n)
+		for j, value := range values {
+			if j >= len(rls) || rls[j] == 0 {
+				row++
+			}
+			if row >= len(cells) {
+				break
+			}
+			// A row starting with a null in a repeated column has an empty or missing list
+			if maxRl > 0 && value == nil && (j >= len(rls) || rls[j] == 0) {
+				continue
+			}
+			cells[row] = append(cells[row], formatParquetValue(value, e))
+		}
+
+		for r, c := range cells {
+			if maxRl > 0 {
+				rows[r][i] = fmt.Sprintf("[%s]", strings.Join(c, ", "))
+			} else {
+				rows[r][i] = strings.Join(c, "")
+			}
+		}
+	}
+
+	return header, rows, nil
+}
+
+// The library renames columns to Go identifiers, the original names are shown instead
+func getParquetColumnName(pr *reader.ParquetReader, inPath []string) string {
+	full := append([]string{pr.SchemaHandler.GetRootInName()}, inPath...)
+	ex := pr.SchemaHandler.InPathToExPath[common.PathToStr(full)]
+	if ex == "" {
+		return strings.Join(inPath, ".")
+	}
+
+	return strings.Join(common.StrToPath(ex)[1:], ".")
+}
+
+func getParquetElement(pr *reader.ParquetReader, inPath []string) *parquet.SchemaElement {
+	full := append([]string{pr.SchemaHandler.GetRootInName()}, inPath...)
+	if i, ok := pr.SchemaHandler.MapIndex[common.PathToStr(full)]; ok {
+		return pr.SchemaHandler.SchemaElements[i]
+	}
+
+	return nil
+}
+
+func getLogicalType(e *parquet.SchemaElement) string {
+	if lt := e.GetLogicalType(); lt != nil {
+		switch {
+		case lt.IsSetSTRING():
+			return "STRING"
+		case lt.IsSetMAP():
+			return "MAP"
+		case lt.IsSetLIST():
+			return "LIST"
+		case lt.IsSetENUM():
+			return "ENUM"
+		case lt.IsSetDECIMAL():
+			return fmt.Sprintf("DECIMAL(%v,%v)", lt.DECIMAL.Precision, lt.DECIMAL.Scale)
+		case lt.IsSetDATE():
+			return "DATE"
+		case lt.IsSetTIME():
+			return "TIME"
+		case lt.IsSetTIMESTAMP():
+			return fmt.Sprintf("TIMESTAMP(%s)", getTimeUnit(lt.TIMESTAMP.Unit))
+		case lt.IsSetINTEGER():
+			return fmt.Sprintf("INT(%v,%v)", lt.INTEGER.BitWidth, lt.INTEGER.IsSigned)
+		case lt.IsSetJSON():
+			return "JSON"
+		case lt.IsSetBSON():
+			return "BSON"
+		case lt.IsSetUUID():
+			return "UUID"
+		}
+	}
+
+	if e.ConvertedType != nil {
+		if *e.ConvertedType == parquet.ConvertedType_DECIMAL {
+			return fmt.Sprintf("DECIMAL(%v,%v)", e.GetPrecision(), e.GetScale())
+		}
+		return e.ConvertedType.String()
+	}
+
+	return ""
+}
+
+func getTimeUnit(u *parquet.TimeUnit) string {
+	switch {
+	case u == nil:
+		return ""
+	case u.MILLIS != nil:
+		return "MILLIS"
+	case u.MICROS != nil:
+		return "MICROS"
+	default:
+		return "NANOS"
+	}
+}
+
+func formatParquetStatistic(b []byte, t parquet.Type, e *parquet.SchemaElement) string {
+	if b == nil {
+		return ""
+	}
+
+	switch {
+	case t == parquet.Type_INT32 && len(b) == 4:
+		return formatParquetValue(int32(binary.LittleEndian.Uint32(b)), e)
+	case t == parquet.Type_INT64 && len(b) == 8:
+		return formatParquetValue(int64(binary.LittleEndian.Uint64(b)), e)
+	case t == parquet.Type_FLOAT && len(b) == 4:
+		return formatParquetValue(math.Float32frombits(binary.LittleEndian.Uint32(b)), e)
+	case t == parquet.Type_DOUBLE && len(b) == 8:
+		return formatParquetValue(math.Float64frombits(binary.LittleEndian.Uint64(b)), e)
+	case t == parquet.Type_BOOLEAN && len(b) == 1:
+		return formatParquetValue(b[0] != 0, e)
+	default:
+		return formatParquetValue(string(b), e)
+	}
+}
+
+func formatParquetValue(value interface{}, e *parquet.SchemaElement) string {
+	var ct parquet.ConvertedType = -1
+	var lt *parquet.LogicalType
+	if e != nil {
+		if e.ConvertedType != nil {
+			ct = *e.ConvertedType
+		}
+		lt = e.GetLogicalType()
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "null"
+
+	case int32:
+		switch {
+		case ct == parquet.ConvertedType_DATE || (lt != nil && lt.IsSetDATE()):
+			return time.Unix(int64(v)*86400, 0).UTC().Format("2006-01-02")
+		case ct == parquet.ConvertedType_DECIMAL:
+			return pqtypes.DECIMAL_INT_ToString(int64(v), int(e.GetPrecision()), int(e.GetScale()))
+		}
+		return strconv.FormatInt(int64(v), 10)
+
+	case int64:
+		switch {
+		case ct == parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return time.UnixMilli(v).UTC().Format(time.RFC3339Nano)
+		case ct == parquet.ConvertedType_TIMESTAMP_MICROS:
+			return time.UnixMicro(v).UTC().Format(time.RFC3339Nano)
+		case lt != nil && lt.IsSetTIMESTAMP():
+			switch getTimeUnit(lt.TIMESTAMP.Unit) {
+			case "MILLIS":
+				return time.UnixMilli(v).UTC().Format(time.RFC3339Nano)
+			case "MICROS":
+				return time.UnixMicro(v).UTC().Format(time.RFC3339Nano)
+			default:
+				return time.Unix(0, v).UTC().Format(time.RFC3339Nano)
+			}
+		case ct == parquet.ConvertedType_DECIMAL:
+			return pqtypes.DECIMAL_INT_ToString(v, int(e.GetPrecision()), int(e.GetScale()))
+		}
+		return strconv.FormatInt(v, 10)
+
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+
+	case bool:
+		return strconv.FormatBool(v)
+
+	case string:
+		switch {
+		case e != nil && e.GetType() == parquet.Type_INT96 && len(v) == 12:
+			return pqtypes.INT96ToTime(v).UTC().Format(time.RFC3339Nano)
+		case ct == parquet.ConvertedType_DECIMAL:
+			return pqtypes.DECIMAL_BYTE_ARRAY_ToString([]byte(v), int(e.GetPrecision()), int(e.GetScale()))
+		case !utf8.ValidString(v):
+			return "0x" + hex.EncodeToString([]byte(v))
+		}
+		return sanitizeLine(v)
+	}
+
+	return sanitizeLine(fmt.Sprint(value))
+}
+
+// Columns are as wide as their longest value, within limits
+func newSizedTable(header []string, rows []table.Row) *table.Model {
+	columns := make([]table.Column, len(header))
+	for i, h := range header {
+		w := lipgloss.Width(h)
+		for _, r := range rows {
+			if i < len(r) && lipgloss.Width(r[i]) > w {
+				w = lipgloss.Width(r[i])
+			}
+		}
+
+		// The table cuts values 5 characters before the column width
+		w += 5
+		if w < minCsvColumnWidth {
+			w = minCsvColumnWidth
+		}
+		if w > maxCsvColumnWidth {
+			w = maxCsvColumnWidth
+		}
+		columns[i] = table.Column{Name: sanitizeLine(h), Width: w, PlainText: true}
+	}
+
+	t := table.New(columns, false)
+	t.EnableHorizontalScroll()
+	t.SetData(rows)
+
+	return t
+}
+
+func (v *parquetViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.isLoading {
+		return lineNumberStyle.Render("Reading the footer")
+	}
+
+	tabs := make([]string, len(parquetTabs))
+	for i, t := range parquetTabs {
+		style := tabStyle
+		if i == v.tab {
+			style = activeTabStyle
+		}
+		tabs[i] = style.Render(fmt.Sprintf("%v %s", i+1, t))
+	}
+	bar := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+
+	var content string
+	switch {
+	case v.tab == parquetRowsTab && v.rowsErr != nil:
+		content = errorStyle.Render(v.rowsErr.Error())
+	case v.tab == parquetRowsTab && v.rowsLoading:
+		content = lineNumberStyle.Render("Decoding the first rows")
+	default:
+		content = v.currentTable().View()
+	}
+
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Top, lipgloss.JoinVertical(lipgloss.Center, bar, content))
+}
+
+func (v *parquetViewer) status() string {
+	if v.pr == nil {
+		return "parquet"
+	}
+
+	return fmt.Sprintf(
+		"%v rows • %v row groups • %v columns",
+		v.pr.GetNumRows(),
+		len(v.pr.Footer.GetRowGroups()),
+		len(v.pr.SchemaHandler.ValueColumns))
+}
+
+func (v *parquetViewer) help() string {
+	return help.GetParquetPreviewHelp()
+}
+
+func (v *parquetViewer) isCapturingKeys() bool {
+	return false
+}
@@ -0,0 +1,315 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/dialog"
+	"s3-viewer/ui/components/help"
+	spin "s3-viewer/ui/components/spinner"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+var (
+	model *previewModel
+)
+
+type previewModel struct {
+	key       string
+	info      *api.ObjectInfo
+	spinner   spinner.Model
+	isLoading bool
+	err       error
+	viewer    viewer
+	src       source   // Nil for viewers doing their own reads
+	archive   string   // Archive object the previewed file is in, empty for s3 objects
+	keys      []string // Objects previewed together, empty when there is only one
+	data      []byte   // Contents of the file of the archive
+}
+
+// Every kind of preview renders the object in the area between the header and the help
+type viewer interface {
+	update(m *types.UiModel, msg tea.Msg) tea.Cmd
+	view() string
+	setSize(width, height int)
+	status() string
+	help() string
+	// True while the viewer needs esc for itself, e.g. to close its search prompt
+	isCapturingKeys() bool
+}
+
+// Viewers that leave something on the screen outside of their view, like images drawn by the terminal.
+// The command cleans it up.
+type closer interface {
+	close() tea.Cmd
+}
+
+type objectInfoMsg struct {
+	info *api.ObjectInfo
+	head []byte // The first bytes of uncompressed objects
+	data []byte // The whole file when it comes from an archive
+	err  error
+}
+
+// Files of archives are read into memory to be previewed, they can not be read with ranged GETs
+const maxMemberSize = 64 * 1024 * 1024
+
+func Init(m *types.UiModel) tea.Cmd {
+	model = &previewModel{
+		key:       m.GetCurrentObject(),
+		spinner:   spin.GetSpinner(),
+		isLoading: true,
+	}
+
+	bucket := m.GetCurrentBucket()
+	key := model.key
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.spinner.Tick)
+	// Several objects are always logs to analyze together
+	if keys := m.GetCurrentObjects(); len(keys) > 0 {
+		model.keys = keys
+		model.isLoading = false
+		var cmd tea.Cmd
+		model.viewer, cmd = newLogViewer(m, keys)
+		cmds = append(cmds, cmd)
+		return tea.Batch(cmds...)
+	}
+
+	if m.GetCurrentMember() != "" {
+		model.archive = key
+		model.key = m.GetCurrentMember()
+		cmds = append(cmds, loadMember(m, bucket, key, model.key))
+		return tea.Batch(cmds...)
+	}
+
+	cmds = append(cmds, func() tea.Msg {
+		info, err := api.GetObjectInfo(m.Session, bucket, key)
+		if err != nil {
+			return objectInfoMsg{err: err}
+		}
+
+		// Failing to sniff the object only means it is shown as text
+		var head []byte
+		if c, _ := getCompression(key, info.ContentEncoding); c == "" && info.Size > 0 {
+			end := int64(sniffSize)
+			if end > info.Size {
+				end = info.Size
+			}
+			head, _ = api.GetObjectRange(m.Session, bucket, key, 0, end-1)
+		}
+
+		return objectInfoMsg{info, head, nil, nil}
+	})
+
+	return tea.Batch(cmds...)
+}
+
+func loadMember(m *types.UiModel, bucket, archive, member string) tea.Cmd {
+	return func() tea.Msg {
+		ai, err := api.GetObjectInfo(m.Session, bucket, archive)
+		if err != nil {
+			return objectInfoMsg{err: err}
+		}
+
+		data, err := api.ReadArchiveMember(m.Session, bucket, archive, ai.Size, member, maxMemberSize)
+		if errors.Is(err, api.ErrMemberTooLarge) {
+			err = fmt.Errorf("files bigger than %s can not be previewed from an archive, download it instead", utils.GetFriendlyByteDisplay(maxMemberSize))
+		}
+		if err != nil {
+			return objectInfoMsg{err: err}
+		}
+
+		info := &api.ObjectInfo{
+			Size:        int64(len(data)),
+			ContentType: mime.TypeByExtension(path.Ext(member)),
+		}
+		head := data
+		if len(head) > sniffSize {
+			head = head[:sniffSize]
+		}
+
+		return objectInfoMsg{info, head, data, nil}
+	}
+}
+
+func Update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msg := msg.(type) {
+	case objectInfoMsg:
+		model.isLoading = false
+		if msg.err != nil {
+			model.err = msg.err
+			break
+		}
+
+		model.info = msg.info
+		model.data = msg.data
+		var cmd tea.Cmd
+		model.viewer, cmd = newViewer(m, model.key, msg.info, msg.head)
+		cmds = append(cmds, cmd)
+
+	case tea.KeyMsg:
+		if model.viewer == nil || !model.viewer.isCapturingKeys() {
+			switch msg.String() {
+			case "esc", "q":
+				if c, ok := model.viewer.(closer); ok {
+					return tea.Batch(c.close(), m.CloseCurrentObject())
+				}
+				return m.CloseCurrentObject()
+			}
+		}
+	}
+
+	if model.viewer != nil {
+		width, height := getBodySize()
+		model.viewer.setSize(width, height)
+		cmds = append(cmds, model.viewer.update(m, msg))
+	}
+
+	if model.isLoading {
+		var sc tea.Cmd
+		model.spinner, sc = model.spinner.Update(msg)
+		cmds = append(cmds, sc)
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func View(m *types.UiModel) string {
+	if model.isLoading {
+		return dialog.GetLoadingDialog(fmt.Sprintf("Loading %s", model.key), model.spinner)
+	}
+
+	width, _, _ := term.GetSize(int(os.Stdout.Fd()))
+
+	if model.err != nil {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			renderHeader(width, ""),
+			errorStyle.Render(model.err.Error()),
+			help.GetPreviewErrorHelp())
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		renderHeader(width, model.viewer.status()),
+		model.viewer.view(),
+		model.viewer.help())
+}
+
+// Picks how the object is shown
+func newViewer(m *types.UiModel, key string, info *api.ObjectInfo, head []byte) (viewer, tea.Cmd) {
+	// Compressed objects are picked by the name they have once decompressed
+	compression, name := getCompression(key, info.ContentEncoding)
+	open := func() objectReader {
+		if model.data != nil {
+			return bytes.NewReader(model.data)
+		}
+		return api.NewObjectReader(m.Session, m.GetCurrentBucket(), key, info.Size)
+	}
+	if isCloudTrail(key) {
+		return newCloudTrailViewer(m, []string{key})
+	}
+	if compression == "" && isParquet(key, info.ContentType) {
+		return newParquetViewer(open)
+	}
+	if compression == "" && isImage(key, info.ContentType) {
+		return newImageViewer(open, info)
+	}
+	if compression == "" && isAccessLog(key, head) {
+		return newAccessLogViewer(m, []string{key})
+	}
+	if compression == "" && isBinary(head) {
+		return newHexViewer(open, info)
+	}
+
+	model.src = newSource(m, key, info)
+	if isCsv(name, info.ContentType) {
+		return newCsvViewer(model.src, name, info)
+	}
+
+	if isMarkdown(name, info.ContentType) && info.Size <= maxMarkdownSize {
+		return newMarkdownViewer(model.src, name, info)
+	}
+
+	kind := getDocumentKind(name, info.ContentType)
+	// NDJSON is read record by record so it has no size limit
+	if kind == ndjsonDocument || (kind != "" && info.Size <= maxTreeSize) {
+		return newTreeViewer(model.src, name, info, kind)
+	}
+
+	v, cmd := newTextViewer(model.src, name, info)
+	// Only plain objects can be followed, appends to compressed ones can not be decompressed on their own
+	if src, ok := model.src.(*rangeSource); ok {
+		v.follow = newFollower(m.Session, m.GetCurrentBucket(), key, info, src)
+	}
+
+	return v, cmd
+}
+
+// The viewers get the whole terminal except for the header and help lines
+func getBodySize() (int, int) {
+	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+
+	return width, height - 2
+}
+
+func renderHeader(width int, status string) string {
+	info := ""
+	if model.info != nil {
+		info = utils.GetFriendlyByteDisplay(model.info.Size)
+		if d, ok := model.src.(*decompressSource); ok {
+			size := utils.GetFriendlyByteDisplay(d.Decompressed())
+			if !d.Done() {
+				size += "+"
+			}
+			info = fmt.Sprintf("%s %s → %s", d.Compression(), info, size)
+		}
+		if model.info.ContentType != "" {
+			info = fmt.Sprintf("%s • %s", info, model.info.ContentType)
+		}
+	}
+
+	right := ""
+	if info != "" {
+		right += headerInfoStyle.Render(info)
+	}
+	if status != "" {
+		right += headerStatusStyle.Render(status)
+	}
+
+	// The key gets whatever room is left and is cut from the left so the file name stays visible
+	key := model.key
+	if model.archive != "" {
+		key = fmt.Sprintf("%s › %s", model.archive, key)
+	}
+	if len(model.keys) > 1 {
+		key = fmt.Sprintf("%s and %v more", key, len(model.keys)-1)
+	}
+	room := width - lipgloss.Width(right) - 2
+	if room > 3 && lipgloss.Width(key) > room {
+		r := []rune(key)
+		key = "..." + string(r[len(r)-room+3:])
+	}
+	left := headerKeyStyle.Render(key)
+
+	gap := width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 0 {
+		gap = 0
+	}
+
+	return left + headerStyle.Render(strings.Repeat(" ", gap)) + right
+}
@@ -0,0 +1,94 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Pixels left out of the sixel image
+const sixelTransparent = 255
+
+// Writes the image as sixel with a fixed 6x6x6 color cube, good enough for a preview and it needs no
+// quantization pass
+func writeSixel(s *strings.Builder, img *image.RGBA) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	// P2=1 leaves the transparent pixels alone
+	s.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(s, "\"1;1;%d;%d", width, height)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(s, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+
+	colors := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			if c.A < 128 {
+				colors[y*width+x] = sixelTransparent
+				continue
+			}
+			colors[y*width+x] = uint8(toCubeLevel(c.R)*36 + toCubeLevel(c.G)*6 + toCubeLevel(c.B))
+		}
+	}
+
+	// Every band is 6 pixels high, it is drawn once per color it uses
+	for band := 0; band < height; band += 6 {
+		used := make([]bool, 216)
+		for i := band * width; i < (band+6)*width && i < len(colors); i++ {
+			if colors[i] != sixelTransparent {
+				used[colors[i]] = true
+			}
+		}
+
+		first := true
+		for c := range used {
+			if !used[c] {
+				continue
+			}
+			// $ goes back to the start of the band for the next color
+			if !first {
+				s.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(s, "#%d", c)
+
+			var last byte
+			run := 0
+			for x := 0; x < width; x++ {
+				var bits byte
+				for r := 0; r < 6 && band+r < height; r++ {
+					if colors[(band+r)*width+x] == uint8(c) {
+						bits |= 1 << r
+					}
+				}
+				if ch := 63 + bits; ch == last {
+					run++
+				} else {
+					writeSixelRun(s, last, run)
+					last, run = ch, 1
+				}
+			}
+			writeSixelRun(s, last, run)
+		}
+		s.WriteByte('-')
+	}
+
+	s.WriteString("\x1b\\")
+}
+
+func writeSixelRun(s *strings.Builder, ch byte, run int) {
+	switch {
+	case run == 0:
+	case run > 3:
+		fmt.Fprintf(s, "!%d%c", run, ch)
+	default:
+		s.WriteString(strings.Repeat(string(ch), run))
+	}
+}
+
+func toCubeLevel(v uint8) int {
+	return (int(v)*5 + 127) / 255
+}
@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"io"
+	"s3-viewer/api"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// Size of every ranged read, small enough to show the first screen quickly
+const chunkSize = 64 * 1024
+
+// A source hands out an object in chunks so the viewers never have to download the whole object.
+// Next returns io.EOF once everything has been read, Offset is how many bytes of the object were read so far.
+// Done is true when the next call would only return io.EOF.
+type source interface {
+	Next() ([]byte, error)
+	Offset() int64
+	Done() bool
+}
+
+// Random access to the previewed object, an s3 object read with ranged GETs or a file of an archive
+// that was read into memory
+type objectReader interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	Size() int64
+}
+
+// Opens a new reader with its own position, for viewers reading from more than one place at a time
+type openFunc func() objectReader
+
+// Hands out an object that is already in memory, like a file of an archive
+type memorySource struct {
+	data   []byte
+	offset int64
+}
+
+func (s *memorySource) Next() ([]byte, error) {
+	if s.Done() {
+		return nil, io.EOF
+	}
+
+	end := s.offset + chunkSize
+	if end > int64(len(s.data)) {
+		end = int64(len(s.data))
+	}
+	b := s.data[s.offset:end]
+	s.offset = end
+
+	return b, nil
+}
+
+func (s *memorySource) Offset() int64 {
+	return s.offset
+}
+
+func (s *memorySource) Done() bool {
+	return s.offset >= int64(len(s.data))
+}
+
+// Reads the object with consecutive byte range requests
+type rangeSource struct {
+	session *session.Session
+	bucket  string
+	key     string
+	offset  int64
+	size    int64
+}
+
+func newRangeSource(session *session.Session, bucket, key string, size int64) *rangeSource {
+	return &rangeSource{
+		session: session,
+		bucket:  bucket,
+		key:     key,
+		size:    size,
+	}
+}
+
+func (s *rangeSource) Next() ([]byte, error) {
+	if s.offset >= s.size {
+		return nil, io.EOF
+	}
+
+	end := s.offset + chunkSize - 1
+	if end >= s.size {
+		end = s.size - 1
+	}
+
+	b, err := api.GetObjectRange(s.session, s.bucket, s.key, s.offset, end)
+	if err != nil {
+		return nil, err
+	}
+	s.offset += int64(len(b))
+
+	return b, nil
+}
+
+func (s *rangeSource) Offset() int64 {
+	return s.offset
+}
+
+func (s *rangeSource) Done() bool {
+	return s.offset >= s.size
+}
@@ -0,0 +1,69 @@
+package preview
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	headerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#3C3836"))
+
+	headerKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#F25D93")).
+			Padding(0, 1)
+
+	headerInfoStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#6124DF")).
+			Padding(0, 1)
+
+	headerStatusStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#5CC1F7")).
+				Padding(0, 1)
+
+	lineNumberStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240"))
+
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#FCA17D"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff4754")).
+			Padding(1, 2)
+
+	searchStyle = lipgloss.NewStyle().
+			Padding(0, 1)
+
+	errorLineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff4754")).
+			Padding(0, 1)
+
+	treeCursorStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("#3C3836"))
+
+	treeKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5CC1F7"))
+
+	treeSummaryStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("240"))
+
+	treeStringStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#A6E22E"))
+
+	treeNumberStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#AE81FF"))
+
+	treeBoolStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FCA17D"))
+
+	tabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Padding(0, 2)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#6124DF")).
+			Padding(0, 2)
+)
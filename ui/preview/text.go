@@ -0,0 +1,426 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wrap"
+)
+
+// A pager for text objects.  The object is read a chunk at a time, the next chunk is requested once
+// the viewport gets close to the end of what has been loaded.
+type textViewer struct {
+	src         source
+	size        int64
+	data        []byte
+	lines       []string
+	lineOffsets []int // First rendered row of every line, only differs from the line index when wrapping
+	isLoading   bool
+	eof         bool
+	err         error
+	viewport    viewport.Model
+	width       int
+	lineNumbers bool
+	wrap        bool
+	searchInput textinput.Model
+	isSearching bool
+	search      *regexp.Regexp
+	matches     []int // Lines containing the search term
+	matchIndex  int
+	lexer       chroma.Lexer // Nil shows plain text
+	theme       int
+	highlighted []string  // Syntax highlighted lines
+	follow      *follower // Nil when the object can not be followed
+}
+
+type textChunkMsg struct {
+	src  source
+	data []byte
+	err  error
+}
+
+func newTextViewer(src source, key string, info *api.ObjectInfo) (*textViewer, tea.Cmd) {
+	si := textinput.New()
+	si.Prompt = "/"
+	si.Placeholder = "search"
+
+	v := &textViewer{
+		src:         src,
+		size:        info.Size,
+		viewport:    viewport.New(0, 0),
+		searchInput: si,
+		theme:       getInitialTheme(),
+	}
+	if info.Size <= maxHighlightSize {
+		v.lexer = getLexer(key, info.ContentType)
+	}
+
+	return v, v.loadMore()
+}
+
+func (v *textViewer) loadMore() tea.Cmd {
+	if v.isLoading || v.eof || v.err != nil {
+		return nil
+	}
+
+	v.isLoading = true
+	src := v.src
+	return func() tea.Msg {
+		b, err := src.Next()
+		return textChunkMsg{src, b, err}
+	}
+}
+
+func (v *textViewer) setSize(width, height int) {
+	if v.isSearching {
+		height--
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	v.viewport.Height = height
+	if width != v.width {
+		v.width = width
+		v.viewport.Width = width
+		v.render()
+	}
+}
+
+func (v *textViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+
+	switch msg := msg.(type) {
+	case textChunkMsg:
+		// A chunk of an object that was closed in the meantime
+		if msg.src != v.src {
+			return nil
+		}
+		v.handleChunk(msg)
+		cmds = append(cmds, v.keepFollowing())
+
+	case followTickMsg:
+		if msg.f != v.follow {
+			return nil
+		}
+		return v.handleFollowTick()
+
+	case followCheckMsg:
+		if msg.f != v.follow {
+			return nil
+		}
+		return v.handleFollowCheck(msg)
+
+	case tea.KeyMsg:
+		if v.isSearching {
+			return v.handleSearchKey(msg)
+		}
+
+		switch msg.String() {
+		case "/":
+			v.isSearching = true
+			v.searchInput.SetValue("")
+			v.searchInput.Focus()
+			return textinput.Blink
+
+		case "esc":
+			v.setSearch(nil)
+			return nil
+
+		case "n":
+			v.jumpToMatch(v.matchIndex + 1)
+
+		case "N":
+			v.jumpToMatch(v.matchIndex - 1)
+
+		case "l":
+			v.lineNumbers = !v.lineNumbers
+			v.render()
+
+		case "w":
+			v.wrap = !v.wrap
+			v.render()
+
+		case "T":
+			if v.lexer != nil {
+				v.theme = (v.theme + 1) % len(themes)
+				v.updateHighlighting()
+				v.render()
+			}
+
+		case "F":
+			if v.follow != nil {
+				return v.toggleFollow()
+			}
+
+		case "g", "home":
+			v.viewport.GotoTop()
+
+		case "G", "end":
+			v.viewport.GotoBottom()
+
+		default:
+			var cmd tea.Cmd
+			v.viewport, cmd = v.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	default:
+		if v.isSearching {
+			var cmd tea.Cmd
+			v.searchInput, cmd = v.searchInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	// Keep a screen of text ahead of the viewport
+	if v.viewport.TotalLineCount()-(v.viewport.YOffset+v.viewport.Height) < v.viewport.Height {
+		cmds = append(cmds, v.loadMore())
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (v *textViewer) handleChunk(msg textChunkMsg) {
+	v.isLoading = false
+	if errors.Is(msg.err, io.EOF) {
+		v.eof = true
+		return
+	}
+	if msg.err != nil {
+		v.err = msg.err
+		return
+	}
+
+	v.data = append(v.data, msg.data...)
+	if v.src.Done() {
+		v.eof = true
+	}
+
+	v.updateLines()
+}
+
+func (v *textViewer) updateLines() {
+	v.lines = strings.Split(string(v.data), "\n")
+	if len(v.lines) > 0 && v.lines[len(v.lines)-1] == "" {
+		v.lines = v.lines[:len(v.lines)-1]
+	}
+	v.findMatches()
+	v.updateHighlighting()
+	v.render()
+}
+
+func (v *textViewer) updateHighlighting() {
+	v.highlighted = nil
+	if v.lexer != nil {
+		v.highlighted = highlightLines(v.lexer, themes[v.theme], string(v.data))
+	}
+}
+
+func (v *textViewer) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		v.isSearching = false
+		v.searchInput.Blur()
+		return nil
+
+	case "enter":
+		v.isSearching = false
+		v.searchInput.Blur()
+
+		var re *regexp.Regexp
+		if t := v.searchInput.Value(); t != "" {
+			re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(t))
+		}
+		v.setSearch(re)
+
+		// Start at the first match below the top of the screen
+		for i, l := range v.matches {
+			if v.lineOffsets[l] >= v.viewport.YOffset {
+				v.jumpToMatch(i)
+				return nil
+			}
+		}
+		v.jumpToMatch(0)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.searchInput, cmd = v.searchInput.Update(msg)
+	return cmd
+}
+
+func (v *textViewer) setSearch(re *regexp.Regexp) {
+	v.search = re
+	v.matchIndex = 0
+	v.findMatches()
+	v.render()
+}
+
+func (v *textViewer) findMatches() {
+	v.matches = nil
+	if v.search == nil {
+		return
+	}
+
+	for i, l := range v.lines {
+		if v.search.MatchString(l) {
+			v.matches = append(v.matches, i)
+		}
+	}
+}
+
+// Moves to the match at i, going round at either end
+func (v *textViewer) jumpToMatch(i int) {
+	if len(v.matches) == 0 {
+		return
+	}
+
+	v.matchIndex = (i + len(v.matches)) % len(v.matches)
+	v.viewport.SetYOffset(v.lineOffsets[v.matches[v.matchIndex]] - v.viewport.Height/3)
+}
+
+func (v *textViewer) render() {
+	numberWidth := len(strconv.Itoa(len(v.lines)))
+	textWidth := v.width
+	if v.lineNumbers {
+		textWidth -= numberWidth + 1
+	}
+
+	matching := make(map[int]bool, len(v.matches))
+	for _, i := range v.matches {
+		matching[i] = true
+	}
+
+	var b strings.Builder
+	v.lineOffsets = make([]int, len(v.lines))
+	row := 0
+	for i, l := range v.lines {
+		v.lineOffsets[i] = row
+
+		// Lines with a search match lose their syntax colors so the match stands out
+		if i < len(v.highlighted) && !matching[i] {
+			l = v.highlighted[i]
+		} else {
+			l = v.highlightMatches(sanitizeLine(l))
+		}
+		parts := []string{l}
+		if textWidth > 0 {
+			if v.wrap {
+				parts = strings.Split(wrap.String(l, textWidth), "\n")
+			} else {
+				parts[0] = truncate.String(l, uint(textWidth))
+			}
+		}
+
+		for j, p := range parts {
+			if v.lineNumbers {
+				n := ""
+				if j == 0 {
+					n = strconv.Itoa(i + 1)
+				}
+				b.WriteString(lineNumberStyle.Render(fmt.Sprintf("%*s ", numberWidth, n)))
+			}
+			b.WriteString(p)
+			b.WriteString("\n")
+			row++
+		}
+	}
+
+	v.viewport.SetContent(strings.TrimSuffix(b.String(), "\n"))
+}
+
+func (v *textViewer) highlightMatches(l string) string {
+	if v.search == nil {
+		return l
+	}
+
+	return v.search.ReplaceAllStringFunc(l, func(s string) string {
+		return matchStyle.Render(s)
+	})
+}
+
+// Tabs become spaces and other control characters dots so the object can not mess up the terminal
+func sanitizeLine(l string) string {
+	l = strings.ToValidUTF8(strings.TrimSuffix(l, "\r"), "�")
+	l = strings.ReplaceAll(l, "\t", "    ")
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '.'
+		}
+		return r
+	}, l)
+}
+
+func (v *textViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.eof && len(v.lines) == 0 {
+		return lineNumberStyle.Render("(empty)")
+	}
+
+	content := v.viewport.View()
+	if v.isSearching {
+		content += "\n" + searchStyle.Render(v.searchInput.View())
+	}
+
+	return content
+}
+
+func (v *textViewer) status() string {
+	s := utils.GetFriendlyByteDisplay(v.src.Offset())
+	if !v.eof {
+		s = fmt.Sprintf("%s of %s loaded", s, utils.GetFriendlyByteDisplay(v.size))
+	} else {
+		s = fmt.Sprintf("%v lines", len(v.lines))
+	}
+
+	if v.lexer != nil {
+		s = fmt.Sprintf("%s • %s", v.lexer.Config().Name, s)
+	}
+
+	if v.follow != nil && v.follow.start > 0 {
+		s = fmt.Sprintf("%s • first %s skipped", s, utils.GetFriendlyByteDisplay(v.follow.start))
+	}
+
+	if v.follow != nil && v.follow.isFollowing {
+		if v.follow.message != "" {
+			s = fmt.Sprintf("%s • %s", v.follow.message, s)
+		}
+		s = fmt.Sprintf("following • %s", s)
+	}
+
+	if v.search != nil {
+		if len(v.matches) == 0 {
+			s = fmt.Sprintf("no matches • %s", s)
+		} else {
+			s = fmt.Sprintf("match %v/%v • %s", v.matchIndex+1, len(v.matches), s)
+		}
+	}
+
+	return s
+}
+
+func (v *textViewer) help() string {
+	return help.GetTextPreviewHelp(v.isSearching, v.search != nil, v.lexer != nil, v.follow != nil)
+}
+
+func (v *textViewer) isCapturingKeys() bool {
+	return v.isSearching || v.search != nil
+}
@@ -0,0 +1,706 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"s3-viewer/api"
+	"s3-viewer/ui/components/help"
+	"s3-viewer/ui/components/icons"
+	"s3-viewer/ui/types"
+	"s3-viewer/ui/utils"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jmespath/go-jmespath"
+	"github.com/muesli/reflow/truncate"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonDocument   = "json"
+	yamlDocument   = "yaml"
+	ndjsonDocument = "ndjson"
+
+	// JSON and YAML have to be parsed in one go, larger documents are shown in the pager instead
+	maxTreeSize = 16 * 1024 * 1024
+)
+
+// A node of the parsed document, objects and arrays have children
+type treeNode struct {
+	key      string
+	value    interface{}
+	children []*treeNode
+	parent   *treeNode
+	depth    int
+	expanded bool
+}
+
+// Explorer for JSON and YAML documents and NDJSON records.  JSON and YAML are loaded completely before
+// they are parsed, NDJSON is read a chunk at a time and every line is shown as a document of its own.
+type treeViewer struct {
+	src        source
+	size       int64
+	kind       string
+	data       []byte
+	isLoading  bool
+	eof        bool
+	err        error
+	records    []string // Complete NDJSON lines loaded so far
+	record     int
+	wantsNext  bool        // The next record was asked for before it was loaded
+	document   interface{} // Current document or record, queries run against it
+	parseErr   error
+	root       *treeNode
+	rows       []*treeNode // Visible nodes, children of collapsed nodes are left out
+	cursor     int
+	offset     int
+	width      int
+	height     int
+	queryInput textinput.Model
+	isQuerying bool
+	query      string
+	queryErr   error
+	message    string // Feedback of the last action, cleared by the next key
+}
+
+type treeChunkMsg struct {
+	src  source
+	data []byte
+	err  error
+}
+
+// Which kind of structured document the object is, empty when it is not one
+func getDocumentKind(key, contentType string) string {
+	switch strings.ToLower(icons.GetExtension(key)) {
+	case "json", "tfstate", "ipynb":
+		return jsonDocument
+	case "yaml", "yml":
+		return yamlDocument
+	case "jsonl", "ndjson":
+		return ndjsonDocument
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/x-ndjson" || mt == "application/jsonl" || mt == "application/x-jsonlines":
+		return ndjsonDocument
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return jsonDocument
+	case strings.Contains(mt, "yaml"):
+		return yamlDocument
+	}
+
+	return ""
+}
+
+func newTreeViewer(src source, key string, info *api.ObjectInfo, kind string) (*treeViewer, tea.Cmd) {
+	qi := textinput.New()
+	qi.Prompt = "query: "
+	qi.Placeholder = "jmespath expression, e.g. items[?size > `10`].name"
+
+	v := &treeViewer{
+		src:        src,
+		size:       info.Size,
+		kind:       kind,
+		queryInput: qi,
+	}
+
+	return v, v.loadMore()
+}
+
+func (v *treeViewer) loadMore() tea.Cmd {
+	if v.isLoading || v.eof || v.err != nil {
+		return nil
+	}
+
+	v.isLoading = true
+	src := v.src
+	return func() tea.Msg {
+		b, err := src.Next()
+		return treeChunkMsg{src, b, err}
+	}
+}
+
+func (v *treeViewer) setSize(width, height int) {
+	if v.isQuerying || v.queryErr != nil {
+		height--
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	v.width = width
+	v.height = height
+	v.scrollToCursor()
+}
+
+func (v *treeViewer) update(m *types.UiModel, msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case treeChunkMsg:
+		if msg.src != v.src {
+			return nil
+		}
+		return v.handleChunk(msg)
+
+	case tea.KeyMsg:
+		v.message = ""
+		if v.isQuerying {
+			return v.handleQueryKey(msg)
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			v.moveCursor(-1)
+		case "down", "j":
+			v.moveCursor(1)
+		case "pgup", "b":
+			v.moveCursor(-v.height)
+		case "pgdown", "f":
+			v.moveCursor(v.height)
+		case "g", "home":
+			v.moveCursor(-len(v.rows))
+		case "G", "end":
+			v.moveCursor(len(v.rows))
+
+		case "enter", " ":
+			v.toggle()
+		case "right", "l":
+			v.expand()
+		case "left", "h":
+			v.collapse()
+		case "*":
+			v.expandAll()
+
+		case "]":
+			return v.nextRecord()
+		case "[":
+			v.showRecord(v.record - 1)
+
+		case ":":
+			v.isQuerying = true
+			v.queryInput.SetValue(v.query)
+			v.queryInput.CursorEnd()
+			v.queryInput.Focus()
+			return textinput.Blink
+
+		case "esc":
+			v.setQuery("")
+
+		case "y":
+			v.copyValue()
+		}
+
+	default:
+		if v.isQuerying {
+			var cmd tea.Cmd
+			v.queryInput, cmd = v.queryInput.Update(msg)
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+func (v *treeViewer) handleChunk(msg treeChunkMsg) tea.Cmd {
+	v.isLoading = false
+	if errors.Is(msg.err, io.EOF) {
+		v.eof = true
+	} else if msg.err != nil {
+		v.err = msg.err
+		return nil
+	} else {
+		v.data = append(v.data, msg.data...)
+		if v.src.Done() {
+			v.eof = true
+		}
+	}
+
+	if v.kind != ndjsonDocument {
+		// Only the compressed size was checked before, the document can still turn out to be huge
+		if len(v.data) > maxTreeSize {
+			v.err = fmt.Errorf("the decompressed document is larger than %s", utils.GetFriendlyByteDisplay(maxTreeSize))
+			return nil
+		}
+		if !v.eof {
+			return v.loadMore()
+		}
+		v.document, v.parseErr = parseDocument(v.kind, v.data)
+		v.runQuery()
+		return nil
+	}
+
+	// Only complete lines are records, the rest waits for the next chunk unless this was the last one
+	text := string(v.data)
+	if !v.eof {
+		text = text[:strings.LastIndex(text, "\n")+1]
+	}
+	v.records = v.records[:0]
+	for _, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) != "" {
+			v.records = append(v.records, l)
+		}
+	}
+
+	if v.root == nil && v.parseErr == nil && len(v.records) > 0 {
+		v.showRecord(0)
+	} else if v.wantsNext {
+		return v.nextRecord()
+	}
+
+	// A single record can be larger than a chunk
+	if len(v.records) == 0 && !v.eof {
+		return v.loadMore()
+	}
+
+	return nil
+}
+
+func (v *treeViewer) nextRecord() tea.Cmd {
+	if v.kind != ndjsonDocument {
+		return nil
+	}
+
+	v.wantsNext = false
+	if v.record+1 < len(v.records) {
+		v.showRecord(v.record + 1)
+		return nil
+	}
+	if v.eof {
+		v.message = "last record"
+		return nil
+	}
+
+	v.wantsNext = true
+	return v.loadMore()
+}
+
+func (v *treeViewer) showRecord(i int) {
+	if v.kind != ndjsonDocument || i < 0 || i >= len(v.records) {
+		return
+	}
+
+	v.record = i
+	v.document, v.parseErr = parseDocument(jsonDocument, []byte(v.records[i]))
+	v.runQuery()
+}
+
+func parseDocument(kind string, data []byte) (interface{}, error) {
+	if kind != yamlDocument {
+		// Numbers are kept as written, ids and timestamps above 2^53 do not survive a float64
+		var doc interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid character after the top-level value")
+		}
+		return doc, nil
+	}
+
+	// A YAML stream with several documents is shown as a list of them
+	docs := make([]interface{}, 0)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, normalizeYaml(doc))
+	}
+
+	if len(docs) == 1 {
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// Turns what the YAML decoder produces into the types encoding/json would with UseNumber
+func normalizeYaml(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, c := range v {
+			v[k] = normalizeYaml(c)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, c := range v {
+			m[fmt.Sprint(k)] = normalizeYaml(c)
+		}
+		return m
+	case []interface{}:
+		for i, c := range v {
+			v[i] = normalizeYaml(c)
+		}
+		return v
+	case int, int64, uint64:
+		return json.Number(fmt.Sprint(v))
+	case float64, string, bool, nil:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jmespath only compares float64 numbers.  Numbers a float64 holds exactly are converted for the query,
+// larger ones stay as written so they are not shown rounded in the result.
+func toQueryValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, c := range v {
+			m[k] = toQueryValue(c)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, c := range v {
+			l[i] = toQueryValue(c)
+		}
+		return l
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			i, err := v.Int64()
+			if err != nil || i > 1<<53 || i < -(1<<53) {
+				return v
+			}
+			return float64(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (v *treeViewer) handleQueryKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		v.isQuerying = false
+		v.queryInput.Blur()
+		return nil
+
+	case "enter":
+		v.isQuerying = false
+		v.queryInput.Blur()
+		v.setQuery(strings.TrimSpace(v.queryInput.Value()))
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.queryInput, cmd = v.queryInput.Update(msg)
+	return cmd
+}
+
+func (v *treeViewer) setQuery(q string) {
+	v.query = q
+	v.runQuery()
+}
+
+// Rebuilds the tree from the current document, or from the query result when there is a query
+func (v *treeViewer) runQuery() {
+	v.queryErr = nil
+	if v.parseErr != nil {
+		v.root = nil
+		v.rows = nil
+		return
+	}
+
+	value := v.document
+	if v.query != "" {
+		result, err := jmespath.Search(v.query, toQueryValue(v.document))
+		if err != nil {
+			v.queryErr = err
+		} else {
+			value = result
+		}
+	}
+
+	v.root = newTreeNode("", value, nil)
+	v.cursor = 0
+	v.offset = 0
+	v.refreshRows()
+}
+
+func newTreeNode(key string, value interface{}, parent *treeNode) *treeNode {
+	n := &treeNode{key: key, value: value, parent: parent}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+	// The first levels are open so there is something to look at right away
+	n.expanded = n.depth < 2
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			n.children = append(n.children, newTreeNode(k, v[k], n))
+		}
+	case []interface{}:
+		for i, c := range v {
+			n.children = append(n.children, newTreeNode(fmt.Sprintf("[%d]", i), c, n))
+		}
+	}
+
+	return n
+}
+
+func (v *treeViewer) refreshRows() {
+	v.rows = v.rows[:0]
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		v.rows = append(v.rows, n)
+		if n.expanded {
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	if v.root != nil {
+		walk(v.root)
+	}
+
+	if v.cursor >= len(v.rows) {
+		v.cursor = len(v.rows) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	v.scrollToCursor()
+}
+
+func (v *treeViewer) moveCursor(delta int) {
+	v.cursor += delta
+	if v.cursor >= len(v.rows) {
+		v.cursor = len(v.rows) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	v.scrollToCursor()
+}
+
+func (v *treeViewer) scrollToCursor() {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.height > 0 && v.cursor >= v.offset+v.height {
+		v.offset = v.cursor - v.height + 1
+	}
+}
+
+func (v *treeViewer) current() *treeNode {
+	if v.cursor < len(v.rows) {
+		return v.rows[v.cursor]
+	}
+	return nil
+}
+
+func (v *treeViewer) toggle() {
+	if n := v.current(); n != nil && len(n.children) > 0 {
+		n.expanded = !n.expanded
+		v.refreshRows()
+	}
+}
+
+func (v *treeViewer) expand() {
+	n := v.current()
+	if n == nil || len(n.children) == 0 {
+		return
+	}
+
+	if n.expanded {
+		v.moveCursor(1)
+		return
+	}
+	n.expanded = true
+	v.refreshRows()
+}
+
+// Collapses the node or moves to its parent when it is already collapsed
+func (v *treeViewer) collapse() {
+	n := v.current()
+	if n == nil {
+		return
+	}
+
+	if n.expanded && len(n.children) > 0 {
+		n.expanded = false
+		v.refreshRows()
+		return
+	}
+
+	for i, r := range v.rows {
+		if r == n.parent {
+			v.cursor = i
+			v.scrollToCursor()
+			return
+		}
+	}
+}
+
+func (v *treeViewer) expandAll() {
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		n.expanded = true
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+
+	if n := v.current(); n != nil {
+		walk(n)
+		v.refreshRows()
+	}
+}
+
+// Strings are copied as they are, everything else as indented JSON
+func (v *treeViewer) copyValue() {
+	n := v.current()
+	if n == nil {
+		return
+	}
+
+	s, ok := n.value.(string)
+	if !ok {
+		b, err := json.MarshalIndent(n.value, "", "  ")
+		if err != nil {
+			v.message = err.Error()
+			return
+		}
+		s = string(b)
+	}
+
+	if err := utils.CopyToClipboard(s); err != nil {
+		v.message = fmt.Sprintf("copy failed: %s", err)
+		return
+	}
+	v.message = "value copied"
+}
+
+func (v *treeViewer) view() string {
+	if v.err != nil {
+		return errorStyle.Render(v.err.Error())
+	}
+	if v.parseErr != nil {
+		return errorStyle.Render(fmt.Sprintf("Could not parse the %s: %s", v.kind, v.parseErr))
+	}
+	if v.root == nil {
+		if v.eof {
+			return lineNumberStyle.Render("(empty)")
+		}
+		return lineNumberStyle.Render(fmt.Sprintf(
+			"Loading %s of %s",
+			utils.GetFriendlyByteDisplay(v.src.Offset()),
+			utils.GetFriendlyByteDisplay(v.size)))
+	}
+
+	lines := make([]string, 0, v.height+1)
+	for i := v.offset; i < len(v.rows) && i < v.offset+v.height; i++ {
+		l := truncate.String(renderTreeRow(v.rows[i]), uint(v.width))
+		if i == v.cursor {
+			l = treeCursorStyle.Render(l)
+		}
+		lines = append(lines, l)
+	}
+	// Keep the prompt at the bottom of the screen
+	for len(lines) < v.height {
+		lines = append(lines, "")
+	}
+
+	if v.isQuerying {
+		lines = append(lines, searchStyle.Render(v.queryInput.View()))
+	} else if v.queryErr != nil {
+		lines = append(lines, errorLineStyle.Render(truncate.String(v.queryErr.Error(), uint(v.width))))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderTreeRow(n *treeNode) string {
+	marker := "  "
+	if len(n.children) > 0 {
+		marker = "▸ "
+		if n.expanded {
+			marker = "▾ "
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat("  ", n.depth))
+	b.WriteString(marker)
+	if n.key != "" {
+		b.WriteString(treeKeyStyle.Render(sanitizeLine(n.key)))
+		b.WriteString(": ")
+	}
+	b.WriteString(renderTreeValue(n))
+
+	return b.String()
+}
+
+func renderTreeValue(n *treeNode) string {
+	switch v := n.value.(type) {
+	case map[string]interface{}:
+		return treeSummaryStyle.Render(fmt.Sprintf("{%v keys}", len(v)))
+	case []interface{}:
+		return treeSummaryStyle.Render(fmt.Sprintf("[%v items]", len(v)))
+	case string:
+		return treeStringStyle.Render(sanitizeLine(strconv.Quote(v)))
+	case json.Number:
+		return treeNumberStyle.Render(v.String())
+	case float64:
+		return treeNumberStyle.Render(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		return treeBoolStyle.Render(strconv.FormatBool(v))
+	case nil:
+		return treeSummaryStyle.Render("null")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (v *treeViewer) status() string {
+	s := v.kind
+	if v.kind == ndjsonDocument && len(v.records) > 0 {
+		loaded := ""
+		if !v.eof {
+			loaded = "+"
+		}
+		s = fmt.Sprintf("record %v/%v%s", v.record+1, len(v.records), loaded)
+	}
+	if v.query != "" {
+		s = fmt.Sprintf("%s • %s", v.query, s)
+	}
+	if v.message != "" {
+		s = fmt.Sprintf("%s • %s", v.message, s)
+	}
+
+	return s
+}
+
+func (v *treeViewer) help() string {
+	return help.GetTreePreviewHelp(v.isQuerying, v.query != "", v.kind == ndjsonDocument)
+}
+
+func (v *treeViewer) isCapturingKeys() bool {
+	return v.isQuerying || v.query != ""
+}
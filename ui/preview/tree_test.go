@@ -0,0 +1,64 @@
+package preview
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+func TestParseDocumentKeepsNumbers(t *testing.T) {
+	tests := []struct {
+		kind string
+		data string
+		want interface{}
+	}{
+		{jsonDocument, `{"id": 1234567890123456789}`, map[string]interface{}{"id": json.Number("1234567890123456789")}},
+		{jsonDocument, `[1.50, -2e3]`, []interface{}{json.Number("1.50"), json.Number("-2e3")}},
+		{yamlDocument, "id: 1234567890123456789\nratio: 0.5\n", map[string]interface{}{"id": json.Number("1234567890123456789"), "ratio": 0.5}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDocument(tt.kind, []byte(tt.data))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseDocument(%q, %q) = %#v, %v", tt.kind, tt.data, got, err)
+		}
+	}
+
+	for _, data := range []string{`{"a": 1} x`, `{"a": 1} {}`, `{"a":`} {
+		if _, err := parseDocument(jsonDocument, []byte(data)); err == nil {
+			t.Errorf("parseDocument(%q) was accepted", data)
+		}
+	}
+}
+
+func TestToQueryValue(t *testing.T) {
+	tests := []struct {
+		in   json.Number
+		want interface{}
+	}{
+		{"42", 42.0},
+		{"-9007199254740992", -9007199254740992.0},
+		{"9007199254740993", json.Number("9007199254740993")},
+		{"123456789012345678901234567890", json.Number("123456789012345678901234567890")},
+		{"1.5", 1.5},
+		{"1e3", 1000.0},
+	}
+
+	for _, tt := range tests {
+		if got := toQueryValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("toQueryValue(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+
+	doc, _ := parseDocument(jsonDocument, []byte(`{"items": [{"size": 5, "id": 9007199254740993}, {"size": 20, "id": 2}]}`))
+	got, err := jmespath.Search("items[?size > `10`].id", toQueryValue(doc))
+	if err != nil || !reflect.DeepEqual(got, []interface{}{2.0}) {
+		t.Errorf("query = %#v, %v", got, err)
+	}
+	got, _ = jmespath.Search("items[0].id", toQueryValue(doc))
+	if got != json.Number("9007199254740993") {
+		t.Errorf("large id = %#v", got)
+	}
+}
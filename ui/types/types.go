@@ -11,6 +11,7 @@ const (
 	Creds   CurrentPage = "creds"
 	Buckets             = "buckets"
 	Files               = "files"
+	Preview             = "preview"
 )
 
 type CurrentPage string
@@ -29,10 +30,13 @@ type ChangeCurrentPathMsg struct {
 // This is the main model used for the overall UI and for
 // pages to pass information back and forth to each other.
 type UiModel struct {
-	Session       *session.Session
-	currentPage   CurrentPage
-	currentBucket string
-	currentPath   string
+	Session        *session.Session
+	currentPage    CurrentPage
+	currentBucket  string
+	currentPath    string
+	currentObject  string
+	currentMember  string   // File inside of the current object when it is an archive
+	currentObjects []string // Objects opened together, like log files analyzed as one
 }
 
 func GetInitialModel() *UiModel {
@@ -69,6 +73,18 @@ func (m *UiModel) GetCurrentPath() string {
 	return m.currentPath
 }
 
+func (m *UiModel) GetCurrentObject() string {
+	return m.currentObject
+}
+
+func (m *UiModel) GetCurrentObjects() []string {
+	return m.currentObjects
+}
+
+func (m *UiModel) GetCurrentMember() string {
+	return m.currentMember
+}
+
 func (m *UiModel) SetCurrentPage(currentPage CurrentPage, currentBucket *string) tea.Cmd {
 	if currentBucket != nil {
 		m.currentBucket = *currentBucket
@@ -77,6 +93,9 @@ func (m *UiModel) SetCurrentPage(currentPage CurrentPage, currentBucket *string)
 	}
 
 	m.currentPath = ""
+	m.currentObject = ""
+	m.currentMember = ""
+	m.currentObjects = nil
 
 	return func() tea.Msg {
 		m.currentPage = currentPage
@@ -96,3 +115,46 @@ func (m *UiModel) SetCurrentPath(path string) tea.Cmd {
 		}
 	}
 }
+
+// Opens the preview page for key.  Unlike SetCurrentPage the bucket and path are kept so closing the
+// preview returns to the same folder.
+func (m *UiModel) SetCurrentObject(key string) tea.Cmd {
+	m.currentObject = key
+	m.currentMember = ""
+	m.currentObjects = nil
+
+	return func() tea.Msg {
+		m.currentPage = Preview
+		return ChangeCurrentPageMsg{
+			CurrentPage:   m.currentPage,
+			CurrentBucket: m.currentBucket,
+		}
+	}
+}
+
+// Opens the preview page for a file inside of the archive key
+func (m *UiModel) SetCurrentMember(key, member string) tea.Cmd {
+	cmd := m.SetCurrentObject(key)
+	m.currentMember = member
+
+	return cmd
+}
+
+// Opens the preview page for several objects at once, closing it returns to the first one
+func (m *UiModel) SetCurrentObjects(keys []string) tea.Cmd {
+	cmd := m.SetCurrentObject(keys[0])
+	m.currentObjects = keys
+
+	return cmd
+}
+
+// Leaves the preview page and goes back to the folder the object was opened from
+func (m *UiModel) CloseCurrentObject() tea.Cmd {
+	return func() tea.Msg {
+		m.currentPage = Files
+		return ChangeCurrentPageMsg{
+			CurrentPage:   m.currentPage,
+			CurrentBucket: m.currentBucket,
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"golang.org/x/term"
+)
+
+// Copies s to the clipboard of the terminal with an OSC 52 escape sequence.  The sequence travels
+// with the output so this also works over ssh.  tmux and screen need the sequence wrapped to pass it
+// through to the outer terminal.
+//
+// Not every terminal understands OSC 52 and there is no way to tell, so when running locally the
+// system clipboard is written as well.  It is also the fallback when the sequence can not be written.
+func CopyToClipboard(s string) error {
+	seq := osc52.New(s)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+
+	_, err := seq.WriteTo(os.Stderr)
+	isLocal := os.Getenv("SSH_TTY") == "" && os.Getenv("SSH_CONNECTION") == ""
+
+	if err != nil || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return clipboard.WriteAll(s)
+	}
+
+	if isLocal && !clipboard.Unsupported {
+		// OSC 52 was written so a failing system clipboard is not an error
+		clipboard.WriteAll(s)
+	}
+
+	return nil
+}
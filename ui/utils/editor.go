@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Builds the command that opens path in the user's editor.  $VISUAL wins over $EDITOR and vi is used
+// when neither is set.  The variable may contain arguments, e.g. "code --wait".
+func GetEditorCmd(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	args = append(args, path)
+
+	return exec.Command(args[0], args[1:]...)
+}
+
+// Creates an empty temp file that keeps the extension of key so editors pick the right syntax
+func CreateTempFileFor(key string) (*os.File, error) {
+	return os.CreateTemp("", "s3-viewer-*"+filepath.Ext(key))
+}
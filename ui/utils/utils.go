@@ -1,6 +1,24 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Maximum number of per-key errors listed in a result dialog
+const MaxListedErrors = 10
+
+// Lists the failures one per line, capped so the result dialog still fits on the screen
+func WriteFailures[T any](b *strings.Builder, failed []T) {
+	for i, f := range failed {
+		if i == MaxListedErrors {
+			fmt.Fprintf(b, "\n... and %v more", len(failed)-MaxListedErrors)
+			break
+		}
+		fmt.Fprintf(b, "\n%v", f)
+	}
+}
 
 func GetFriendlyByteDisplay(b int64) string {
 	const unit = 1000
@@ -17,3 +35,38 @@ func GetFriendlyByteDisplay(b int64) string {
 	return fmt.Sprintf("%.1f %cB",
 		float64(b)/float64(div), "kMGTPE"[exp])
 }
+
+// Parses user input of the form "key=value, other=value" as used for metadata and tags
+func ParseKeyValues(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%q is not in the form key=value", strings.TrimSpace(pair))
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+
+	return m, nil
+}
+
+// The reverse of ParseKeyValues, keys are sorted so the output is stable
+func FormatKeyValues(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, m[k])
+	}
+
+	return strings.Join(pairs, ", ")
+}
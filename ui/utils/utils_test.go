@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  map[string]string
+		isErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"   ", map[string]string{}, false},
+		{"a=1", map[string]string{"a": "1"}, false},
+		{" a = 1 , b=2", map[string]string{"a": "1", "b": "2"}, false},
+		{"a=", map[string]string{"a": ""}, false},
+		{"a=x=y", map[string]string{"a": "x=y"}, false},
+		{"a=1,a=2", map[string]string{"a": "2"}, false},
+		{"a", nil, true},
+		{"=1", nil, true},
+		{"a=1,", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseKeyValues(tt.in)
+		if (err != nil) != tt.isErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKeyValues(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatKeyValues(t *testing.T) {
+	in := map[string]string{"b": "2", "a": "1"}
+	if got := FormatKeyValues(in); got != "a=1, b=2" {
+		t.Errorf("FormatKeyValues(%v) = %q", in, got)
+	}
+
+	got, err := ParseKeyValues(FormatKeyValues(in))
+	if err != nil || !reflect.DeepEqual(got, in) {
+		t.Errorf("ParseKeyValues(FormatKeyValues(%v)) = %v, %v", in, got, err)
+	}
+}